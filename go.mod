@@ -8,6 +8,7 @@ require (
 	github.com/inconshreveable/go-update v0.0.0-20160112193335-8152e7eb6ccf
 	github.com/kardianos/service v1.2.2
 	github.com/spf13/cobra v1.9.1
+	github.com/yuin/goldmark v1.8.2
 	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 )
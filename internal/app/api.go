@@ -0,0 +1,233 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+// APIEvent is the JSON representation of an event served by /api/events.
+type APIEvent struct {
+	UID          string        `json:"uid"`
+	RecurrenceID string        `json:"recurrenceId,omitempty"`
+	Calendar     string        `json:"calendar"`
+	Summary      string        `json:"summary"`
+	Description  string        `json:"description,omitempty"`
+	Location     string        `json:"location,omitempty"`
+	Start        time.Time     `json:"start"`
+	End          time.Time     `json:"end,omitzero"`
+	AllDay       bool          `json:"allDay,omitempty"`
+	Cancelled    bool          `json:"cancelled,omitempty"`
+	CancelReason string        `json:"cancelReason,omitempty"`
+	Closure      bool          `json:"closure,omitempty"`
+	Availability *Availability `json:"availability,omitempty"`
+}
+
+var cancelledPrefix = map[string]string{
+	"de": "Entfällt: ",
+	"en": "Cancelled: ",
+}
+
+// newAPIEvent converts an event for the JSON API with its times in loc. All-day events keep
+// their UTC midnight dates.
+func newAPIEvent(e CalendarEvent, loc *time.Location) APIEvent {
+	start, end := e.Start, e.End
+	if !e.AllDay {
+		start, end = start.In(loc), end.In(loc)
+	}
+	return APIEvent{
+		UID:          e.UID,
+		RecurrenceID: e.RecurrenceID,
+		Calendar:     e.Calendar,
+		Summary:      e.Summary,
+		Description:  e.Description,
+		Location:     e.Location,
+		Start:        start,
+		End:          end,
+		AllDay:       e.AllDay,
+		Cancelled:    e.Cancelled,
+		CancelReason: e.CancelReason,
+		Closure:      e.IsClosure,
+		Availability: apiAvailability(e),
+	}
+}
+
+// eventsAPIHandler serves the upcoming events of the selected calendars as JSON,
+// optionally limited with the days and limit query parameters. Times carry the offset of the
+// tz query parameter or of the display time zone.
+func eventsAPIHandler(w http.ResponseWriter, r *http.Request) {
+	now := clock()
+	loc, ok := queryLocation(r)
+	if !ok {
+		loc = displayLocation
+	}
+	filter := parseEventFilter(r.URL.Query(), 0)
+	events := filter.apply(fetchCalendarEvents(filter.calendars, now), now)
+	result := make([]APIEvent, len(events))
+	for i, e := range events {
+		result[i] = newAPIEvent(e, loc)
+	}
+	slog.Debug("serve events api", "calendars", filter.calendars, "events", len(result))
+	allowEmbedOrigin(w, r)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		slog.Error("encode events", "err", err)
+	}
+}
+
+// icsHandler serves the upcoming events of the selected calendars as an iCal feed.
+// The alarm query parameter adds a reminder that many minutes before each event, 0 disables
+// the configured reminders.
+func icsHandler(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	selectedCalendars, _ := getSelectedCalendars(r.URL.Query().Get("calendar"))
+	now := clock()
+	events := fetchCalendarEvents(selectedCalendars, now)
+	alarm := parseAlarm(r.URL.Query().Get("alarm"))
+	cal := buildICSCalendar(events, selectedCalendars, lang, now, alarm)
+	slog.Debug("serve ics", "calendars", selectedCalendars, "events", len(events), "alarm", alarm)
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if err := cal.SerializeTo(w); err != nil {
+		slog.Error("serialize ics", "err", err)
+	}
+}
+
+// calendarSubscribeURL returns the webcal link to the iCal feed of cal served by the site, so
+// that subscribers get its reminders and closures.
+func calendarSubscribeURL(cal, lang string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(siteBaseURL(), "https://"), "http://")
+	return "webcal://" + host + "/api/calendar.ics?" + url.Values{"calendar": {cal}, "lang": {lang}}.Encode()
+}
+
+// parseAlarm returns the reminder minutes requested with the alarm query parameter, or -1 if
+// the configured defaults apply.
+func parseAlarm(value string) int {
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes < 0 || minutes > maxAlarmMinutes {
+		return -1
+	}
+	return minutes
+}
+
+// alarmMinutes returns how many minutes before e a reminder is due, 0 for none. A negative
+// alarm falls back to the default of the event's calendar.
+func alarmMinutes(e CalendarEvent, alarm int) int {
+	if e.Cancelled || e.IsClosure {
+		return 0
+	}
+	if alarm < 0 {
+		return config.Alarms[e.Calendar]
+	}
+	return alarm
+}
+
+// buildICSCalendar converts events into an iCal calendar. Cancelled events keep their slot
+// with STATUS:CANCELLED and closures become all-day events. Other events get a VALARM as
+// chosen by alarm, see alarmMinutes.
+func buildICSCalendar(events []CalendarEvent, calendars []string, lang string, now time.Time, alarm int) *ical.Calendar {
+	cal := ical.NewCalendar()
+	cal.SetProductId("-//Yang Tai Chi Hamburg//ytc-server//" + strings.ToUpper(lang))
+	cal.SetMethod(ical.MethodPublish)
+	cal.SetXWRCalName("Yang Tai Chi Hamburg: " + strings.Join(calendars, ", "))
+	cal.SetXWRTimezone(displayLocation.String())
+	for _, e := range events {
+		uid := e.UID
+		if e.RecurrenceID != "" {
+			uid += "-" + e.RecurrenceID
+		}
+		ev := cal.AddEvent(uid)
+		ev.SetDtStampTime(now)
+		if e.AllDay {
+			ev.SetAllDayStartAt(e.Start)
+			if !e.End.IsZero() {
+				ev.SetAllDayEndAt(e.End)
+			}
+		} else {
+			ev.SetStartAt(e.Start)
+			if !e.End.IsZero() {
+				ev.SetEndAt(e.End)
+			}
+		}
+		if e.IsClosure {
+			ev.SetTimeTransparency(ical.TransparencyTransparent)
+		}
+		summary := e.Summary
+		if e.Cancelled {
+			summary = cancelledPrefix[lang] + summary
+			ev.SetStatus(ical.ObjectStatusCancelled)
+		}
+		ev.SetSummary(summary)
+		if e.Description != "" {
+			ev.SetDescription(e.Description)
+		}
+		if e.Location != "" {
+			ev.SetLocation(e.Location)
+		}
+		if e.Calendar != "" {
+			ev.AddCategory(e.Calendar)
+		}
+		if minutes := alarmMinutes(e, alarm); minutes > 0 {
+			a := ev.AddAlarm()
+			a.SetAction(ical.ActionDisplay)
+			a.SetTrigger(fmt.Sprintf("-PT%dM", minutes))
+			a.SetProperty(ical.ComponentPropertyDescription, summary)
+		}
+	}
+	return cal
+}
+
+// ListEvents writes the upcoming events of the given calendars (comma-separated, the default
+// selection of the calendar page if empty) within the next days as a table, JSON or iCal.
+func ListEvents(w io.Writer, calendarParam string, days int, lang, format string) error {
+	if format != "table" && format != "json" && format != "ics" {
+		return fmt.Errorf("unknown format %q", format)
+	}
+	if !isSupportedLang(lang) {
+		lang = defaultLang
+	}
+	now := clock()
+	calendars, _ := getSelectedCalendars(calendarParam)
+	events := eventFilter{days: days}.apply(fetchCalendarEvents(calendars, now), now)
+	switch format {
+	case "json":
+		result := make([]APIEvent, len(events))
+		for i, e := range events {
+			result[i] = newAPIEvent(e, displayLocation)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case "ics":
+		return buildICSCalendar(events, calendars, lang, now, -1).SerializeTo(w)
+	}
+	return writeEventTable(w, events, lang)
+}
+
+func writeEventTable(w io.Writer, events []CalendarEvent, lang string) error {
+	l := localeFor(lang)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WHEN\tDURATION\tCALENDAR\tSUMMARY\tLOCATION")
+	for _, e := range events {
+		when, duration := l.timeRange(e.Start.In(displayLocation), e.End.In(displayLocation)), ""
+		if e.AllDay {
+			when = l.dateRange(e.Start, e.End)
+		} else if d := e.Duration(); d > 0 {
+			duration = l.duration(d)
+		}
+		summary := e.Summary
+		if e.Cancelled {
+			summary = cancelledPrefix[lang] + summary
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", when, duration, e.Calendar, summary, e.Location)
+	}
+	return tw.Flush()
+}
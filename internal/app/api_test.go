@@ -0,0 +1,110 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListEvents(t *testing.T) {
+	start := time.Date(2025, 5, 12, 18, 0, 0, 0, displayLocation)
+	clock = func() time.Time { return start.AddDate(0, 0, -2) }
+	defer func() { clock = time.Now }()
+	calendarURLs = map[string]string{"sonderkurse": ""}
+	useTestCalendar(t, "sonderkurse", icsWithEvent("s1", "Workshop", start))
+
+	var table bytes.Buffer
+	if err := ListEvents(&table, "sonderkurse", 30, "de", "table"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), "SUMMARY") || !strings.Contains(table.String(), "Workshop") || !strings.Contains(table.String(), "18:00–19:00") {
+		t.Errorf("unexpected table %q", table.String())
+	}
+
+	var out bytes.Buffer
+	if err := ListEvents(&out, "sonderkurse", 30, "en", "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []APIEvent
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded) != 1 || decoded[0].Summary != "Workshop" {
+		t.Errorf("unexpected JSON %q", out.String())
+	}
+
+	var ics bytes.Buffer
+	if err := ListEvents(&ics, "sonderkurse", 30, "de", "ics"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ics.String(), "BEGIN:VEVENT") {
+		t.Errorf("expected iCal output, got %q", ics.String())
+	}
+
+	table.Reset()
+	if err := ListEvents(&table, "sonderkurse", 1, "de", "table"); err != nil || strings.Contains(table.String(), "Workshop") {
+		t.Errorf("expected event beyond --days to be omitted, got %q", table.String())
+	}
+	if err := ListEvents(&out, "sonderkurse", 30, "de", "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestICSAlarms(t *testing.T) {
+	start := time.Date(2025, 5, 12, 18, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return start.AddDate(0, 0, -2) }
+	defer func() { clock = time.Now }()
+	calendarURLs = map[string]string{"sonderkurse": ""}
+	useTestCalendar(t, "sonderkurse", icsWithEvent("s1", "Workshop", start))
+	config = Config{Alarms: map[string]int{"sonderkurse": 30}}
+	defer func() { config = Config{} }()
+
+	for _, tc := range []struct {
+		query, want string
+	}{
+		{"", "TRIGGER:-PT30M"},
+		{"&alarm=60", "TRIGGER:-PT60M"},
+		{"&alarm=abc", "TRIGGER:-PT30M"},
+		{"&alarm=0", ""},
+	} {
+		w := httptest.NewRecorder()
+		icsHandler(w, httptest.NewRequest("GET", "/api/calendar.ics?calendar=sonderkurse"+tc.query, nil))
+		body := w.Body.String()
+		if tc.want == "" {
+			if strings.Contains(body, "BEGIN:VALARM") {
+				t.Errorf("%q: expected no alarm, got %q", tc.query, body)
+			}
+		} else if !strings.Contains(body, "BEGIN:VALARM") || !strings.Contains(body, tc.want) || !strings.Contains(body, "ACTION:DISPLAY") {
+			t.Errorf("%q: expected %s, got %q", tc.query, tc.want, body)
+		}
+	}
+
+	if alarmMinutes(CalendarEvent{Calendar: "sonderkurse", Cancelled: true}, 60) != 0 {
+		t.Error("expected no alarm for cancelled event")
+	}
+}
+
+func TestCalendarSubscribeLinks(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	config = Config{BaseURL: "https://example.org/"}
+	defer func() { config = Config{} }()
+	calendarURLs = map[string]string{"sonderkurse": "webcal://calendars.example/sonderkurse"}
+
+	var b strings.Builder
+	if err := templatesByLang["de"].ExecuteTemplate(&b, "calendar.html", buildTemplateData("de", "", nil, map[string]bool{})); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	body := b.String()
+	for _, want := range []string{
+		`href="webcal://example.org/api/calendar.ics?calendar=sonderkurse&amp;lang=de"`,
+		`href="webcal://example.org/api/calendar.ics?calendar=sonderkurse&amp;lang=de&amp;alarm=60"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in %q", want, body)
+		}
+	}
+	if strings.Contains(body, "calendars.example") {
+		t.Error("expected the subscribe link to bypass the source feed")
+	}
+}
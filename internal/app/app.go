@@ -48,14 +48,68 @@ var calendarBtnClasses = map[string]string{
 	"ferienkurse":      "danger",
 }
 
+// calendarOrder is the order of the built-in calendars on the calendar page; calendars
+// added through sources follow in alphabetical order.
+var calendarOrder = []string{"wochenkurse", "sonderkurse", "schnupperstunden", "ferienkurse"}
+
+const (
+	defaultCalendarColor    = "#6c757d"
+	defaultCalendarBtnClass = "secondary"
+)
+
 type CalendarEvent struct {
+	UID          string
+	RecurrenceID string
+	Status       string
+	Summary      string
+	Description  string
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	Location     string
+	Instructor   string
+	Calendar     string
+	Cancelled    bool
+	CancelReason string
+	IsClosure    bool
+	Capacity     int
+	Booked       int
+	Waitlist     bool
+}
+
+// NewsItem is an item of the news calendar or a Markdown news post.
+type NewsItem struct {
+	CalendarEvent
+	// Lang limits a news item to one language; empty means all.
+	Lang string
+	// Pinned news items are listed first; Expires hides them from then on.
+	Pinned  bool
+	Expires time.Time
+	// Content is the rendered body of a Markdown news post.
+	Content template.HTML
+	// Slug names a news item in its permalink /news/{year}/{slug}.
+	Slug string
+	// TextLang is the language of Summary and Description, Translations holds the news text
+	// in other languages and Fallback is set to TextLang when no translation was available.
+	TextLang     string
+	Translations map[string]NewsText
+	Fallback     string
+	// Image is the URL of the header image of a news item.
+	Image string
+}
+
+// NewsText is the summary and description of a news item in one language.
+type NewsText struct {
 	Summary     string
 	Description string
-	Start       string
-	End         string
-	Location    string
-	Duration    string
-	Calendar    string
+}
+
+// Duration returns the length of the event, or zero if it has no end.
+func (e CalendarEvent) Duration() time.Duration {
+	if e.Start.IsZero() || e.End.IsZero() {
+		return 0
+	}
+	return e.End.Sub(e.Start)
 }
 
 type TemplateData struct {
@@ -67,13 +121,13 @@ type TemplateData struct {
 	CalColors     map[string]string
 	ActiveCals    map[string]bool
 	CalBtnClasses map[string]string
-	CalWebcalURLs map[string]string
-}
-
-type eventWithTime struct {
-	CalendarEvent
-	startTime time.Time
-	endTime   time.Time
+	// CalSubscribeURLs holds the webcal link to the served iCal feed of each calendar.
+	CalSubscribeURLs map[string]string
+	Changes          []Change
+	TimeZone         string
+	TimeZones        []string
+	// Banner is the news item shown in the dismissible banner and the footer ticker, if any.
+	Banner *NewsItem
 }
 
 type DownloadFile struct {
@@ -83,9 +137,10 @@ type DownloadFile struct {
 }
 
 type DownloadTemplateData struct {
-	Page  string
-	Lang  string
-	Files []DownloadFile
+	Page   string
+	Lang   string
+	Files  []DownloadFile
+	Banner *NewsItem
 }
 
 func Server(port string, sslPort string, certFile string, keyFile string, domain string, email string) error {
@@ -103,14 +158,30 @@ func Server(port string, sslPort string, certFile string, keyFile string, domain
 	}
 
 	loadTemplates()
-	http.HandleFunc("/", makeLangHandler("home.html"))
-	http.HandleFunc("/home", makeLangHandler("home.html"))
+	setupWebhooks(config)
+	calendarChanges.watch(changeWatchInterval)
+	newsCache.start(newsRefreshInterval)
+	http.HandleFunc("/", homeHandler)
+	http.HandleFunc("/home", homeHandler)
 	http.HandleFunc("/about", makeLangHandler("about.html"))
 	http.HandleFunc("/news", newsHandler)
+	http.HandleFunc("/news/{year}/{slug}", newsItemHandler)
+	http.HandleFunc("/news/archive/{year}", newsArchiveHandler)
+	http.HandleFunc("/news/images/{key}", newsImageHandler)
+	http.HandleFunc("/news/visits.js", newsVisitsHandler)
+	http.HandleFunc("/api/news/latest", newsLatestHandler)
+	http.HandleFunc("/news.rss", rssHandler)
+	http.HandleFunc("/news.atom", atomHandler)
+	http.HandleFunc("/news.json", jsonFeedHandler)
 	http.HandleFunc("/calendar", calendarHandler)
 	http.HandleFunc("/taichi", makeLangHandler("taichi.html"))
 	http.HandleFunc("/impressum", makeLangHandler("impressum.html"))
 	http.HandleFunc("/download", downloadHandler)
+	http.HandleFunc("/api/changes", changesHandler)
+	http.HandleFunc("/api/events", eventsAPIHandler)
+	http.HandleFunc("/api/calendar.ics", icsHandler)
+	http.HandleFunc("/embed/calendar", embedCalendarHandler)
+	http.HandleFunc("/embed/calendar.js", embedLoaderHandler)
 
 	imagesSub, err := fs.Sub(imagesFS, "static/images")
 	if err != nil {
@@ -152,9 +223,9 @@ func makeLangHandler(page string) http.HandlerFunc {
 			return
 		}
 		data := TemplateData{
-			Page:          strings.TrimSuffix(page, ".html"),
-			Lang:          lang,
-			CalWebcalURLs: calendarURLs,
+			Page:   strings.TrimSuffix(page, ".html"),
+			Lang:   lang,
+			Banner: bannerNews(lang, clock()),
 		}
 		slog.Debug("renderTemplate", "lang", lang, "page", page)
 		if err := tmpl.ExecuteTemplate(w, page, data); err != nil {
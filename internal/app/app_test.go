@@ -112,21 +112,21 @@ func TestParseEvent(t *testing.T) {
 	event.SetProperty(ical.ComponentPropertySummary, "summary")
 	event.SetProperty(ical.ComponentPropertyDescription, "desc")
 	event.SetProperty(ical.ComponentPropertyLocation, "loc")
-	calEvent, start, end := parseEvent(event, "wochenkurse")
+	calEvent := parseEvent(event, "wochenkurse")
 	if calEvent.Summary != "summary" || calEvent.Description != "desc" || calEvent.Location != "loc" {
 		t.Error("parseEvent did not parse fields")
 	}
-	if start.IsZero() || end.IsZero() {
+	if calEvent.Start.IsZero() || calEvent.End.IsZero() || calEvent.AllDay {
 		t.Error("parseEvent did not parse times")
 	}
-	if calEvent.Duration == "" {
-		t.Error("parseEvent did not set duration")
+	if calEvent.Duration() != time.Hour {
+		t.Errorf("parseEvent did not set duration, got %v", calEvent.Duration())
 	}
 }
 
 func TestFetchEventsForCalendar_Empty(t *testing.T) {
 	calendarURLs = map[string]string{"wochenkurse": "webcal://invalid-url"}
-	events := fetchEventsForCalendar("wochenkurse", time.Now())
+	events := fetchEventsForCalendar("wochenkurse", time.Now(), time.Now().AddDate(0, 0, expansionDays))
 	if len(events) != 0 {
 		t.Error("expected no events on error")
 	}
@@ -134,15 +134,15 @@ func TestFetchEventsForCalendar_Empty(t *testing.T) {
 
 func TestFetchCalendarEvents_Sorting(t *testing.T) {
 	now := time.Now()
-	eventsWithTime := []eventWithTime{
-		{CalendarEvent: CalendarEvent{Summary: "b"}, startTime: now.Add(2 * time.Hour)},
-		{CalendarEvent: CalendarEvent{Summary: "a"}, startTime: now.Add(1 * time.Hour)},
+	events := []CalendarEvent{
+		{Summary: "b", Start: now.Add(2 * time.Hour)},
+		{Summary: "a", Start: now.Add(1 * time.Hour)},
 	}
-	sort.Slice(eventsWithTime, func(i, j int) bool {
-		return eventsWithTime[i].startTime.Before(eventsWithTime[j].startTime)
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
 	})
-	if eventsWithTime[0].Summary != "a" || eventsWithTime[1].Summary != "b" {
-		t.Error("eventWithTime sorting by startTime failed")
+	if events[0].Summary != "a" || events[1].Summary != "b" {
+		t.Error("CalendarEvent sorting by Start failed")
 	}
 }
 
@@ -171,14 +171,22 @@ func TestParseEventNews(t *testing.T) {
 	event.SetProperty(ical.ComponentPropertyDtStart, "20240102T150405Z")
 	event.SetProperty(ical.ComponentPropertySummary, "summary")
 	event.SetProperty(ical.ComponentPropertyDescription, "desc")
-	calEvent, start, _ := parseEventNews(event)
+	calEvent := parseEventNews(event)
 	if calEvent.Summary != "summary" || calEvent.Description != "desc" {
 		t.Error("parseEventNews did not parse fields")
 	}
-	if start.IsZero() {
-		t.Error("parseEventNews did not parse start time")
+	if !calEvent.Start.Equal(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("parseEventNews did not parse start time, got %v", calEvent.Start)
 	}
-	if calEvent.Start != "2.1." {
-		t.Errorf("parseEventNews did not format date as expected, got %q", calEvent.Start)
+}
+
+func TestFetchCalendarEventsExpandsRecurrences(t *testing.T) {
+	calendarURLs = map[string]string{"wochenkurse": ""}
+	useTestCalendar(t, "wochenkurse", weeklyICS)
+	now := time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)
+	events := fetchCalendarEvents([]string{"wochenkurse"}, now)
+	// The series started before now; only May 26 and June 2 and 9 are still ahead.
+	if len(events) != 3 || events[0].Start.Day() != 26 || events[0].RecurrenceID == "" {
+		t.Errorf("expected the remaining occurrences of the series, got %+v", events)
 	}
 }
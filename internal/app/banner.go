@@ -0,0 +1,70 @@
+package app
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	newsRefreshInterval = 5 * time.Minute
+	// newsBannerMaxAge is how long after publication an ordinary news item is shown in the
+	// banner; pinned and expiring items are shown for as long as they are active.
+	newsBannerMaxAge = 14 * 24 * time.Hour
+)
+
+// newsStore keeps the news items fetched in the background, so that the news pages, the
+// feeds and the banner do not wait for the news calendar.
+type newsStore struct {
+	mu     sync.RWMutex
+	events []NewsItem
+}
+
+var newsCache = &newsStore{}
+
+// refresh fetches the news and replaces the stored items and the images they use.
+func (s *newsStore) refresh() {
+	events := fetchNewsEvents()
+	newsImages.retain(events)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = events
+	slog.Debug("refreshed news store", "items", len(events))
+}
+
+// start refreshes the store now and then every interval.
+func (s *newsStore) start(interval time.Duration) {
+	go func() {
+		s.refresh()
+		for range time.Tick(interval) {
+			s.refresh()
+		}
+	}()
+}
+
+// get returns the stored news items, none until the first refresh has run; callers must not
+// modify them.
+func (s *newsStore) get() []NewsItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.events
+}
+
+// bannerNews returns the most recent active news item in lang for the banner, or nil. Items
+// are active once published until they expire; ordinary items only for newsBannerMaxAge.
+func bannerNews(lang string, now time.Time) *NewsItem {
+	var banner *NewsItem
+	for _, e := range newsForLang(newsCache.get(), lang) {
+		if e.Start.IsZero() || e.Start.After(now) || !e.Expires.IsZero() && !now.Before(e.Expires) {
+			continue
+		}
+		if !e.Pinned && e.Expires.IsZero() && now.Sub(e.Start) > newsBannerMaxAge {
+			continue
+		}
+		if banner == nil || e.Start.After(banner.Start) {
+			item := e
+			banner = &item
+		}
+	}
+	return banner
+}
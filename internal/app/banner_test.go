@@ -0,0 +1,65 @@
+package app
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBannerNews(t *testing.T) {
+	now := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return now }
+	defer func() { clock = time.Now }()
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	newsURLs = map[string]string{"news": ""}
+	useTestCalendar(t, "news", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+		"BEGIN:VEVENT\r\nUID:old\r\nSUMMARY:Alt\r\nDTSTART:20250601T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:pinned\r\nSUMMARY:Jubiläum\r\nX-YTC-PINNED:TRUE\r\nDTSTART:20250101T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:recent\r\nSUMMARY:Neue Kurse\r\nDTSTART:20250705T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:english\r\nSUMMARY:[en] New classes\r\nDTSTART:20250708T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:future\r\nSUMMARY:Herbst\r\nDTSTART:20250801T090000Z\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+	newsCache.refresh()
+
+	if b := bannerNews("de", now.Add(-72*time.Hour)); b == nil || b.UID != "recent" {
+		t.Errorf("expected the most recent published item, got %+v", b)
+	}
+	if b := bannerNews("de", now.Add(60*24*time.Hour)); b == nil || b.UID != "pinned" {
+		t.Errorf("expected only the pinned item once the others are old, got %+v", b)
+	}
+
+	w := httptest.NewRecorder()
+	makeLangHandler("about.html")(w, httptest.NewRequest("GET", "/about?lang=en", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `data-news-id="english"`) || !strings.Contains(body, "New classes") {
+		t.Errorf("expected the banner on the about page, got %q", body)
+	}
+
+	calendarURLs = map[string]string{}
+	w = httptest.NewRecorder()
+	homeHandler(w, httptest.NewRequest("GET", "/?lang=de", nil))
+	body = w.Body.String()
+	if !strings.Contains(body, `data-news-id="english"`) || !strings.Contains(body, `class="news-ticker`) {
+		t.Errorf("expected the banner and the footer ticker on the home page, got %q", body)
+	}
+	w = httptest.NewRecorder()
+	newsHandler(w, httptest.NewRequest("GET", "/news?lang=de", nil))
+	if !strings.Contains(w.Body.String(), `class="news-ticker`) {
+		t.Error("expected the footer ticker on the news page")
+	}
+
+	// The pages read the store and never fetch the calendar themselves.
+	newsCache = &newsStore{}
+	w = httptest.NewRecorder()
+	newsHandler(w, httptest.NewRequest("GET", "/news?lang=de", nil))
+	if strings.Contains(w.Body.String(), "Neue Kurse") {
+		t.Error("expected the news page to show the stored news only")
+	}
+	w = httptest.NewRecorder()
+	makeLangHandler("about.html")(w, httptest.NewRequest("GET", "/about?lang=en", nil))
+	if strings.Contains(w.Body.String(), "news-banner") {
+		t.Error("expected no banner without news")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	ical "github.com/arran4/golang-ical"
 	"log/slog"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -11,10 +12,14 @@ import (
 	"github.com/WillyWinkel/ytc/internal/utils"
 )
 
+// expansionDays is how far ahead recurring events are expanded for the pages, the API and the feeds.
+const expansionDays = 365
+
 // calendarHandler handles the main calendar page, rendering events for selected calendars.
 func calendarHandler(w http.ResponseWriter, r *http.Request) {
 	lang := getLang(r)
-	tmpl, ok := templatesByLang[lang]
+	loc := getLocation(w, r)
+	tmpl, ok := templateFor(lang, loc)
 	if !ok {
 		slog.Error("template not found for language", "lang", lang)
 		http.Error(w, "Template not found", http.StatusInternalServerError)
@@ -23,9 +28,12 @@ func calendarHandler(w http.ResponseWriter, r *http.Request) {
 	calendarParam := r.URL.Query().Get("calendar")
 
 	selectedCalendars, activeCals := getSelectedCalendars(calendarParam)
-	events := fetchCalendarEvents(selectedCalendars)
+	events := fetchCalendarEvents(selectedCalendars, clock())
 
 	data := buildTemplateData(lang, calendarParam, events, activeCals)
+	data.Changes = calendarChanges.recent(selectedCalendars, recentChangesOnPage)
+	data.TimeZone = loc.String()
+	data.TimeZones = timeZones
 	slog.Debug("renderTemplate", "lang", lang, "page", "calendar.html", "events", len(events))
 	if err := tmpl.ExecuteTemplate(w, "calendar.html", data); err != nil {
 		slog.Error("render template", "err", err)
@@ -34,17 +42,40 @@ func calendarHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func buildTemplateData(lang, calendarParam string, events []CalendarEvent, activeCals map[string]bool) TemplateData {
+	names := calendarNames()
+	subscribe := make(map[string]string, len(names))
+	for _, name := range names {
+		subscribe[name] = calendarSubscribeURL(name, lang)
+	}
 	return TemplateData{
-		Page:          "calendar",
-		Lang:          lang,
-		Events:        events,
-		Calendar:      calendarParam,
-		Calendars:     []string{"wochenkurse", "sonderkurse", "schnupperstunden", "ferienkurse"},
-		CalColors:     calendarColors,
-		ActiveCals:    activeCals,
-		CalBtnClasses: calendarBtnClasses,
-		CalWebcalURLs: calendarURLs,
+		Page:             "calendar",
+		Lang:             lang,
+		Events:           events,
+		Calendar:         calendarParam,
+		Calendars:        names,
+		CalColors:        calendarColors,
+		ActiveCals:       activeCals,
+		CalBtnClasses:    calendarBtnClasses,
+		CalSubscribeURLs: subscribe,
+		Banner:           bannerNews(lang, clock()),
+	}
+}
+
+// calendarNames returns the configured calendars, the built-in ones first.
+func calendarNames() []string {
+	var names, extra []string
+	for _, name := range calendarOrder {
+		if _, ok := calendarURLs[name]; ok {
+			names = append(names, name)
+		}
+	}
+	for name := range calendarURLs {
+		if !slices.Contains(calendarOrder, name) {
+			extra = append(extra, name)
+		}
 	}
+	sort.Strings(extra)
+	return append(names, extra...)
 }
 
 // getSelectedCalendars returns the selected calendar names and a map of active calendars.
@@ -73,66 +104,60 @@ func getSelectedCalendars(calendarParam string) ([]string, map[string]bool) {
 	return selectedCalendars, activeCals
 }
 
-// fetchCalendarEvents fetches and sorts events for the selected calendars.
-func fetchCalendarEvents(selectedCalendars []string) []CalendarEvent {
-	var eventsWithTime []eventWithTime
-	now := time.Now()
-
+// fetchCalendarEvents fetches the selected calendars up to expansionDays ahead, or to the end
+// of the last closure if that is later, applies closures and sorts the events by start.
+func fetchCalendarEvents(selectedCalendars []string, now time.Time) []CalendarEvent {
+	var events []CalendarEvent
+	closures := loadClosures(now)
+	until := now.AddDate(0, 0, expansionDays)
+	for _, c := range closures {
+		if c.end.After(until) {
+			until = c.end
+		}
+	}
 	for _, calName := range selectedCalendars {
-		calendarEvents := fetchEventsForCalendar(calName, now)
-		eventsWithTime = append(eventsWithTime, calendarEvents...)
+		events = append(events, fetchEventsForCalendar(calName, now, until)...)
 	}
+	applyCapacityOverrides(events, capacityOverrides.get())
+	events = applyClosures(events, closures, config.Closures.Mode)
 
-	sort.Slice(eventsWithTime, func(i, j int) bool {
-		return eventsWithTime[i].startTime.Before(eventsWithTime[j].startTime)
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
 	})
-
-	events := make([]CalendarEvent, len(eventsWithTime))
-	for i, e := range eventsWithTime {
-		events[i] = e.CalendarEvent
-	}
 	return events
 }
 
-// fetchEventsForCalendar fetches and parses events for a single calendar.
-func fetchEventsForCalendar(calName string, now time.Time) []eventWithTime {
+// fetchEventsForCalendar fetches the events of a single calendar that have not ended before
+// from and start before until, with recurring events expanded into their occurrences.
+func fetchEventsForCalendar(calName string, from, until time.Time) []CalendarEvent {
 	calendarURL, ok := calendarURLs[calName]
 	if !ok {
 		slog.Error("calendar not found", "calendar", calName)
 		return nil
 	}
-	calendarURL = strings.ReplaceAll(calendarURL, "webcal://", "https://")
-	cal, err := ical.ParseCalendarFromUrl(calendarURL)
+	cal, err := loadCalendar(calName, calendarURL)
 	if err != nil {
 		slog.Error("parse calendar", "calendar", calName, "err", err)
 		return nil
 	}
-	var events []eventWithTime
-
-	for _, e := range cal.Events() {
-		event, startTime, endTime := parseEvent(e, calName)
-		if calName == "news" || (!endTime.IsZero() && endTime.After(now)) {
-			events = append(events, eventWithTime{
-				CalendarEvent: event,
-				startTime:     startTime,
-				endTime:       endTime,
-			})
-		}
-	}
-	return events
+	return expandCalendar(cal, calName, from, until)
 }
 
 // parseEvent extracts event details from an iCal event.
-func parseEvent(e *ical.VEvent, calName string) (CalendarEvent, time.Time, time.Time) {
+func parseEvent(e *ical.VEvent, calName string) CalendarEvent {
 	var (
-		startStr, endStr, summary, description, location string
-		startTime, endTime                               time.Time
+		summary, description, location string
+		startTime, endTime             time.Time
+		allDay                         bool
 	)
+	uid, recurrenceID, status := parseEventIdentity(e)
+	capacity, booked, waitlist := parseCapacity(e)
 	if prop := e.GetProperty(ical.ComponentPropertyDtStart); prop != nil {
-		startTime, startStr = utils.ParseICalTimeToHuman(prop.Value)
+		startTime = parseEventTime(prop)
+		allDay = isDateValue(prop)
 	}
 	if prop := e.GetProperty(ical.ComponentPropertyDtEnd); prop != nil {
-		endTime, endStr = utils.ParseICalTimeToHuman(prop.Value)
+		endTime = parseEventTime(prop)
 	}
 	if prop := e.GetProperty(ical.ComponentPropertySummary); prop != nil {
 		summary = prop.Value
@@ -143,17 +168,43 @@ func parseEvent(e *ical.VEvent, calName string) (CalendarEvent, time.Time, time.
 	if prop := e.GetProperty(ical.ComponentPropertyLocation); prop != nil {
 		location = prop.Value
 	}
-	duration := ""
-	if !startTime.IsZero() && !endTime.IsZero() {
-		duration = utils.HumanDuration(endTime.Sub(startTime))
-	}
 	return CalendarEvent{
-		Summary:     summary,
-		Description: description,
-		Start:       startStr,
-		End:         endStr,
-		Location:    location,
-		Duration:    duration,
-		Calendar:    calName,
-	}, startTime, endTime
+		UID:          uid,
+		RecurrenceID: recurrenceID,
+		Status:       status,
+		Cancelled:    status == "CANCELLED",
+		Summary:      summary,
+		Description:  description,
+		Start:        startTime,
+		End:          endTime,
+		AllDay:       allDay,
+		Location:     location,
+		Instructor:   parseInstructor(e),
+		Calendar:     calName,
+		Capacity:     capacity,
+		Booked:       booked,
+		Waitlist:     waitlist,
+	}
+}
+
+// isDateValue reports whether a DTSTART or DTEND property holds a date without a time of day.
+func isDateValue(prop *ical.IANAProperty) bool {
+	if values := prop.ICalParameters[string(ical.ParameterValue)]; len(values) > 0 && strings.EqualFold(values[0], "DATE") {
+		return true
+	}
+	return len(prop.Value) == len("20060102")
+}
+
+// parseEventIdentity extracts the UID, RECURRENCE-ID and STATUS of an iCal event.
+func parseEventIdentity(e *ical.VEvent) (uid, recurrenceID, status string) {
+	if prop := e.GetProperty(ical.ComponentPropertyUniqueId); prop != nil {
+		uid = prop.Value
+	}
+	if prop := e.GetProperty(ical.ComponentPropertyRecurrenceId); prop != nil {
+		recurrenceID = prop.Value
+	}
+	if prop := e.GetProperty(ical.ComponentPropertyStatus); prop != nil {
+		status = strings.ToUpper(prop.Value)
+	}
+	return uid, recurrenceID, status
 }
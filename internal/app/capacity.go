@@ -0,0 +1,174 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+const (
+	propertyCapacity = "X-YTC-CAPACITY"
+	propertyBooked   = "X-YTC-BOOKED"
+	propertyWaitlist = "X-YTC-WAITLIST"
+)
+
+// Availability states of an event with a known capacity.
+const (
+	AvailabilityFree     = "free"
+	AvailabilityFull     = "full"
+	AvailabilityWaitlist = "waitlist"
+)
+
+// CapacityOverride holds locally recorded capacity and booking counts for an event.
+// Nil fields keep the value from the feed.
+type CapacityOverride struct {
+	Capacity *int  `json:"capacity"`
+	Booked   *int  `json:"booked"`
+	Waitlist *bool `json:"waitlist"`
+}
+
+// Availability is the JSON representation of the free places of an event.
+type Availability struct {
+	Capacity int    `json:"capacity"`
+	Booked   int    `json:"booked"`
+	Free     int    `json:"free"`
+	Status   string `json:"status"`
+}
+
+// capacityStore loads the overrides file and reloads it when it changes on disk.
+type capacityStore struct {
+	mu        sync.Mutex
+	path      string
+	modTime   time.Time
+	overrides map[string]CapacityOverride
+}
+
+var capacityOverrides = &capacityStore{}
+
+// setPath switches the store to the overrides file at path.
+func (s *capacityStore) setPath(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path = path
+	s.modTime = time.Time{}
+	s.overrides = nil
+}
+
+// get returns the current overrides keyed by UID or "UID|RECURRENCE-ID".
+func (s *capacityStore) get() map[string]CapacityOverride {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" {
+		return nil
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		slog.Error("stat capacity file", "path", s.path, "err", err)
+		return s.overrides
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return s.overrides
+	}
+	overrides, err := readCapacityFile(s.path)
+	if err != nil {
+		slog.Error("read capacity file", "path", s.path, "err", err)
+		return s.overrides
+	}
+	s.overrides = overrides
+	s.modTime = info.ModTime()
+	slog.Info("Loaded capacity overrides", "path", s.path, "events", len(overrides))
+	return s.overrides
+}
+
+func readCapacityFile(path string) (map[string]CapacityOverride, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var overrides map[string]CapacityOverride
+	if err := json.Unmarshal(b, &overrides); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// parseCapacity reads the X-YTC-CAPACITY, X-YTC-BOOKED and X-YTC-WAITLIST properties of an event.
+func parseCapacity(e *ical.VEvent) (capacity, booked int, waitlist bool) {
+	if prop := e.GetProperty(ical.ComponentProperty(propertyCapacity)); prop != nil {
+		capacity, _ = strconv.Atoi(strings.TrimSpace(prop.Value))
+	}
+	if prop := e.GetProperty(ical.ComponentProperty(propertyBooked)); prop != nil {
+		booked, _ = strconv.Atoi(strings.TrimSpace(prop.Value))
+	}
+	if prop := e.GetProperty(ical.ComponentProperty(propertyWaitlist)); prop != nil {
+		waitlist, _ = strconv.ParseBool(strings.TrimSpace(prop.Value))
+	}
+	return capacity, booked, waitlist
+}
+
+// applyCapacityOverrides merges the locally recorded counts into the events.
+func applyCapacityOverrides(events []CalendarEvent, overrides map[string]CapacityOverride) {
+	if len(overrides) == 0 {
+		return
+	}
+	for i := range events {
+		o, ok := overrides[eventKey(events[i])]
+		if !ok {
+			o, ok = overrides[events[i].UID]
+		}
+		if !ok {
+			continue
+		}
+		if o.Capacity != nil {
+			events[i].Capacity = *o.Capacity
+		}
+		if o.Booked != nil {
+			events[i].Booked = *o.Booked
+		}
+		if o.Waitlist != nil {
+			events[i].Waitlist = *o.Waitlist
+		}
+	}
+}
+
+// FreePlaces returns the number of places that can still be booked.
+func (e CalendarEvent) FreePlaces() int {
+	if free := e.Capacity - e.Booked; free > 0 {
+		return free
+	}
+	return 0
+}
+
+// Availability returns "free", "full" or "waitlist", or "" if the capacity is unknown.
+func (e CalendarEvent) Availability() string {
+	switch {
+	case e.Capacity <= 0:
+		return ""
+	case e.FreePlaces() > 0:
+		return AvailabilityFree
+	case e.Waitlist:
+		return AvailabilityWaitlist
+	}
+	return AvailabilityFull
+}
+
+// apiAvailability returns the JSON availability of an event, or nil if its capacity is unknown.
+func apiAvailability(e CalendarEvent) *Availability {
+	status := e.Availability()
+	if status == "" {
+		return nil
+	}
+	return &Availability{
+		Capacity: e.Capacity,
+		Booked:   e.Booked,
+		Free:     e.FreePlaces(),
+		Status:   status,
+	}
+}
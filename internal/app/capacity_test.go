@@ -0,0 +1,102 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+func TestParseEventCapacity(t *testing.T) {
+	event := ical.NewEvent("w1")
+	event.SetProperty(ical.ComponentPropertyDtStart, "20250512T180000Z")
+	event.SetProperty(ical.ComponentProperty(propertyCapacity), "12")
+	event.SetProperty(ical.ComponentProperty(propertyBooked), "9")
+	calEvent := parseEvent(event, "sonderkurse")
+	if calEvent.Capacity != 12 || calEvent.Booked != 9 || calEvent.Waitlist {
+		t.Errorf("unexpected capacity %+v", calEvent)
+	}
+	if calEvent.FreePlaces() != 3 || calEvent.Availability() != AvailabilityFree {
+		t.Errorf("expected 3 free places, got %d (%s)", calEvent.FreePlaces(), calEvent.Availability())
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	tests := []struct {
+		event CalendarEvent
+		want  string
+	}{
+		{CalendarEvent{}, ""},
+		{CalendarEvent{Capacity: 10, Booked: 4}, AvailabilityFree},
+		{CalendarEvent{Capacity: 10, Booked: 10}, AvailabilityFull},
+		{CalendarEvent{Capacity: 10, Booked: 12, Waitlist: true}, AvailabilityWaitlist},
+	}
+	for _, tt := range tests {
+		if got := tt.event.Availability(); got != tt.want {
+			t.Errorf("Availability(%+v) = %q; want %q", tt.event, got, tt.want)
+		}
+	}
+	if apiAvailability(CalendarEvent{}) != nil {
+		t.Error("expected no availability without capacity")
+	}
+	if a := apiAvailability(CalendarEvent{Capacity: 8, Booked: 10}); a.Free != 0 || a.Status != AvailabilityFull {
+		t.Errorf("unexpected availability %+v", a)
+	}
+}
+
+func TestCapacityStoreOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capacity.json")
+	if err := os.WriteFile(path, []byte(`{"w1": {"capacity": 12, "booked": 12}, "w2|20250519T180000Z": {"booked": 5, "waitlist": true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := &capacityStore{}
+	store.setPath(path)
+	events := []CalendarEvent{
+		{UID: "w1"},
+		{UID: "w2", RecurrenceID: "20250519T180000Z", Capacity: 5},
+		{UID: "w3", Capacity: 5},
+	}
+	applyCapacityOverrides(events, store.get())
+	if events[0].Availability() != AvailabilityFull {
+		t.Errorf("expected w1 to be fully booked, got %+v", events[0])
+	}
+	if events[1].Availability() != AvailabilityWaitlist {
+		t.Errorf("expected w2 override by recurrence id, got %+v", events[1])
+	}
+	if events[2].Availability() != AvailabilityFree {
+		t.Errorf("expected w3 to keep feed values, got %+v", events[2])
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.WriteFile(path, []byte(`{"w1": {"capacity": 12, "booked": 2}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.get()["w1"]; got.Booked == nil || *got.Booked != 2 {
+		t.Error("expected changed overrides file to be reloaded")
+	}
+}
+
+func TestCalendarTemplateRendersAvailability(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	events := []CalendarEvent{
+		{Summary: "Workshop A", Calendar: "sonderkurse", Capacity: 10, Booked: 7},
+		{Summary: "Workshop B", Calendar: "sonderkurse", Capacity: 10, Booked: 10},
+		{Summary: "Workshop C", Calendar: "sonderkurse", Capacity: 10, Booked: 10, Waitlist: true},
+	}
+	var b strings.Builder
+	if err := templatesByLang["de"].ExecuteTemplate(&b, "calendar.html", buildTemplateData("de", "", events, map[string]bool{})); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, want := range []string{"3 Plätze frei", "ausgebucht", "Warteliste"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected %q in rendered calendar", want)
+		}
+	}
+}
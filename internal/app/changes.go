@@ -0,0 +1,239 @@
+package app
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/WillyWinkel/ytc/internal/utils"
+)
+
+// ChangeKind classifies a difference between two refreshes of a calendar.
+type ChangeKind string
+
+const (
+	ChangeAdded       ChangeKind = "added"
+	ChangeRemoved     ChangeKind = "removed"
+	ChangeRescheduled ChangeKind = "rescheduled"
+	ChangeRelocated   ChangeKind = "relocated"
+	ChangeCancelled   ChangeKind = "cancelled"
+)
+
+const (
+	maxChanges          = 200
+	recentChangesOnPage = 5
+	changeWatchInterval = 5 * time.Minute
+)
+
+// Change describes a single detected schedule change.
+type Change struct {
+	Kind             ChangeKind `json:"kind"`
+	Calendar         string     `json:"calendar"`
+	UID              string     `json:"uid"`
+	RecurrenceID     string     `json:"recurrenceId,omitempty"`
+	Summary          string     `json:"summary"`
+	Start            time.Time  `json:"start,omitzero"`
+	End              time.Time  `json:"end,omitzero"`
+	Location         string     `json:"location,omitempty"`
+	PreviousStart    time.Time  `json:"previousStart,omitzero"`
+	PreviousEnd      time.Time  `json:"previousEnd,omitzero"`
+	PreviousLocation string     `json:"previousLocation,omitempty"`
+	DetectedAt       time.Time  `json:"detectedAt"`
+}
+
+// changeLog keeps the last known event set per calendar and a bounded history of changes.
+type changeLog struct {
+	mu        sync.Mutex
+	limit     int
+	snapshots map[string]map[string]CalendarEvent
+	changes   []Change // newest first
+	listeners []func([]Change)
+}
+
+var calendarChanges = newChangeLog(maxChanges)
+
+func newChangeLog(limit int) *changeLog {
+	return &changeLog{
+		limit:     limit,
+		snapshots: make(map[string]map[string]CalendarEvent),
+	}
+}
+
+// eventKey identifies an event instance by UID and recurrence id.
+func eventKey(e CalendarEvent) string {
+	return e.UID + "|" + e.RecurrenceID
+}
+
+// subscribe registers fn to be called with every batch of newly detected changes.
+func (l *changeLog) subscribe(fn func([]Change)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, fn)
+}
+
+// watch refreshes the snapshots of all calendars now and then every interval, so that changes
+// are detected whether or not the site is visited.
+func (l *changeLog) watch(interval time.Duration) {
+	go func() {
+		l.refresh(clock())
+		for range time.Tick(interval) {
+			l.refresh(clock())
+		}
+	}()
+}
+
+// refresh loads every calendar and the news calendar and records their changes.
+func (l *changeLog) refresh(now time.Time) {
+	for _, name := range calendarNames() {
+		cal, err := loadCalendar(name, calendarURLs[name])
+		if err != nil {
+			slog.Error("parse calendar", "calendar", name, "err", err)
+			continue
+		}
+		events := make([]CalendarEvent, 0, len(cal.Events()))
+		for _, e := range cal.Events() {
+			events = append(events, parseEvent(e, name))
+		}
+		l.record(name, events, now)
+	}
+	if calendarURL, ok := newsURLs["news"]; ok {
+		cal, err := loadCalendar("news", calendarURL)
+		if err != nil {
+			slog.Error("parse news calendar", "err", err)
+			return
+		}
+		events := make([]CalendarEvent, 0, len(cal.Events()))
+		for _, e := range cal.Events() {
+			events = append(events, parseEventNews(e).CalendarEvent)
+		}
+		l.record("news", events, now)
+	}
+}
+
+// record compares events with the previous snapshot of calName and stores the detected changes.
+// The first snapshot of a calendar only establishes the baseline and yields no changes.
+func (l *changeLog) record(calName string, events []CalendarEvent, now time.Time) []Change {
+	current := make(map[string]CalendarEvent, len(events))
+	for _, e := range events {
+		if e.UID == "" {
+			continue
+		}
+		current[eventKey(e)] = e
+	}
+
+	l.mu.Lock()
+	previous, seen := l.snapshots[calName]
+	l.snapshots[calName] = current
+	if !seen {
+		l.mu.Unlock()
+		return nil
+	}
+
+	detected := diffEvents(calName, previous, current, now)
+	if len(detected) == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	slog.Info("calendar changes detected", "calendar", calName, "changes", len(detected))
+	l.changes = append(detected, l.changes...)
+	if len(l.changes) > l.limit {
+		l.changes = l.changes[:l.limit]
+	}
+	listeners := append([]func([]Change){}, l.listeners...)
+	l.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(detected)
+	}
+	return detected
+}
+
+// diffEvents classifies the differences between two event sets keyed by eventKey.
+func diffEvents(calName string, previous, current map[string]CalendarEvent, now time.Time) []Change {
+	var changes []Change
+	for key, cur := range current {
+		prev, ok := previous[key]
+		if !ok {
+			changes = append(changes, newChange(ChangeAdded, calName, cur, now))
+			continue
+		}
+		if cur.Cancelled && !prev.Cancelled {
+			changes = append(changes, newChange(ChangeCancelled, calName, cur, now))
+			continue
+		}
+		if !cur.Start.Equal(prev.Start) || !cur.End.Equal(prev.End) {
+			c := newChange(ChangeRescheduled, calName, cur, now)
+			c.PreviousStart = prev.Start
+			c.PreviousEnd = prev.End
+			changes = append(changes, c)
+		}
+		if cur.Location != prev.Location {
+			c := newChange(ChangeRelocated, calName, cur, now)
+			c.PreviousLocation = prev.Location
+			changes = append(changes, c)
+		}
+	}
+	for key, prev := range previous {
+		if _, ok := current[key]; !ok {
+			changes = append(changes, newChange(ChangeRemoved, calName, prev, now))
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Start.Before(changes[j].Start)
+	})
+	return changes
+}
+
+func newChange(kind ChangeKind, calName string, e CalendarEvent, now time.Time) Change {
+	return Change{
+		Kind:         kind,
+		Calendar:     calName,
+		UID:          e.UID,
+		RecurrenceID: e.RecurrenceID,
+		Summary:      e.Summary,
+		Start:        e.Start,
+		End:          e.End,
+		Location:     e.Location,
+		DetectedAt:   now,
+	}
+}
+
+// recent returns up to limit of the newest changes, restricted to the given calendars if any.
+func (l *changeLog) recent(calendars []string, limit int) []Change {
+	wanted := make(map[string]bool, len(calendars))
+	for _, c := range calendars {
+		wanted[c] = true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := make([]Change, 0)
+	for _, c := range l.changes {
+		if len(wanted) > 0 && !wanted[c.Calendar] {
+			continue
+		}
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// changesHandler serves the change history as JSON, optionally filtered by calendar and limited.
+func changesHandler(w http.ResponseWriter, r *http.Request) {
+	calendars := utils.SplitAndTrim(r.URL.Query().Get("calendar"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 0 {
+		limit = 0
+	}
+	changes := calendarChanges.recent(calendars, limit)
+	slog.Debug("serve changes", "calendars", calendars, "changes", len(changes))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(changes); err != nil {
+		slog.Error("encode changes", "err", err)
+	}
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEvent(uid, summary, location string, start time.Time) CalendarEvent {
+	return CalendarEvent{UID: uid, Summary: summary, Location: location, Start: start, End: start.Add(time.Hour)}
+}
+
+func TestChangeLogRecord(t *testing.T) {
+	log := newChangeLog(10)
+	base := time.Date(2025, 5, 12, 18, 0, 0, 0, time.UTC)
+	now := base.Add(-48 * time.Hour)
+
+	first := []CalendarEvent{
+		testEvent("a", "Anfänger", "Halle 1", base),
+		testEvent("b", "Fortgeschrittene", "Halle 1", base.Add(24*time.Hour)),
+		testEvent("c", "Push Hands", "Halle 2", base.Add(48*time.Hour)),
+		testEvent("d", "Schwert", "Halle 2", base.Add(72*time.Hour)),
+	}
+	if got := log.record("wochenkurse", first, now); len(got) != 0 {
+		t.Fatalf("first refresh should only set the baseline, got %v", got)
+	}
+
+	cancelled := testEvent("d", "Schwert", "Halle 2", base.Add(72*time.Hour))
+	cancelled.Status, cancelled.Cancelled = "CANCELLED", true
+	second := []CalendarEvent{
+		testEvent("a", "Anfänger", "Halle 1", base.Add(time.Hour)),
+		testEvent("b", "Fortgeschrittene", "Park", base.Add(24*time.Hour)),
+		cancelled,
+		testEvent("e", "Qigong", "Halle 1", base.Add(96*time.Hour)),
+	}
+	got := log.record("wochenkurse", second, now)
+	want := map[string]ChangeKind{
+		"a": ChangeRescheduled,
+		"b": ChangeRelocated,
+		"c": ChangeRemoved,
+		"d": ChangeCancelled,
+		"e": ChangeAdded,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d changes, got %d: %v", len(want), len(got), got)
+	}
+	for _, c := range got {
+		if want[c.UID] != c.Kind {
+			t.Errorf("change for %q = %q; want %q", c.UID, c.Kind, want[c.UID])
+		}
+	}
+	if got[0].UID != "a" || !got[0].PreviousStart.Equal(base) {
+		t.Errorf("expected rescheduled change first with previous start, got %+v", got[0])
+	}
+
+	if again := log.record("wochenkurse", second, now); len(again) != 0 {
+		t.Errorf("unchanged refresh should not yield changes, got %v", again)
+	}
+}
+
+func TestChangeLogBounded(t *testing.T) {
+	log := newChangeLog(3)
+	start := time.Date(2025, 5, 12, 18, 0, 0, 0, time.UTC)
+	log.record("sonderkurse", nil, start)
+	var events []CalendarEvent
+	for i, uid := range []string{"a", "b", "c", "d", "e"} {
+		events = append(events, testEvent(uid, uid, "", start.Add(time.Duration(i)*time.Hour)))
+		log.record("sonderkurse", events, start)
+	}
+	recent := log.recent(nil, 0)
+	if len(recent) != 3 {
+		t.Fatalf("expected history bounded to 3, got %d", len(recent))
+	}
+	if recent[0].UID != "e" {
+		t.Errorf("expected newest change first, got %q", recent[0].UID)
+	}
+	if len(log.recent([]string{"wochenkurse"}, 0)) != 0 {
+		t.Error("expected calendar filter to exclude other calendars")
+	}
+	if len(log.recent(nil, 2)) != 2 {
+		t.Error("expected limit to be applied")
+	}
+}
+
+func TestChangesHandler(t *testing.T) {
+	calendarChanges = newChangeLog(maxChanges)
+	start := time.Date(2025, 5, 12, 18, 0, 0, 0, time.UTC)
+	calendarChanges.record("ferienkurse", nil, start)
+	calendarChanges.record("ferienkurse", []CalendarEvent{testEvent("x", "Sommerkurs", "", start)}, start)
+
+	w := httptest.NewRecorder()
+	changesHandler(w, httptest.NewRequest("GET", "/api/changes?calendar=ferienkurse", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("expected JSON content type, got %q", ct)
+	}
+	var changes []Change
+	if err := json.NewDecoder(w.Body).Decode(&changes); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Kind != ChangeAdded || changes[0].Summary != "Sommerkurs" {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+}
+
+func TestCalendarTemplateRendersChanges(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	start := time.Date(2025, 5, 12, 18, 0, 0, 0, displayLocation)
+	data := buildTemplateData("de", "", nil, map[string]bool{})
+	data.Changes = []Change{{Kind: ChangeRescheduled, Calendar: "wochenkurse", Summary: "Anfänger", Start: start, PreviousStart: start.Add(-time.Hour)}}
+	var b strings.Builder
+	if err := templatesByLang["de"].ExecuteTemplate(&b, "calendar.html", data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(b.String(), "Verschoben") || !strings.Contains(b.String(), "Mo., 12. Mai, 18:00") {
+		t.Error("expected recent changes panel in rendered calendar page")
+	}
+}
+
+func TestChangeLogRefresh(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	calendarChanges = newChangeLog(maxChanges)
+	start := time.Date(2025, 5, 12, 18, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return start.AddDate(0, 0, -2) }
+	defer func() { clock = time.Now }()
+	calendarURLs = map[string]string{"sonderkurse": ""}
+	newsURLs = map[string]string{}
+	useTestCalendar(t, "sonderkurse", icsWithEvent("s1", "Workshop", start))
+
+	calendarChanges.refresh(clock())
+	useTestCalendar(t, "sonderkurse", strings.Replace(icsWithEvent("s1", "Workshop", start), "END:VEVENT", "STATUS:CANCELLED\r\nEND:VEVENT", 1))
+	w := httptest.NewRecorder()
+	calendarHandler(w, httptest.NewRequest("GET", "/calendar?calendar=sonderkurse&lang=de", nil))
+	if changes := calendarChanges.recent(nil, 0); len(changes) != 0 {
+		t.Fatalf("expected page requests not to record changes, got %+v", changes)
+	}
+
+	calendarChanges.refresh(clock())
+	changes := calendarChanges.recent(nil, 0)
+	if len(changes) != 1 || changes[0].Kind != ChangeCancelled {
+		t.Fatalf("expected the cancellation to be recorded, got %+v", changes)
+	}
+	w = httptest.NewRecorder()
+	calendarHandler(w, httptest.NewRequest("GET", "/calendar?calendar=sonderkurse&lang=de", nil))
+	if body := w.Body.String(); !strings.Contains(body, "text-decoration-line-through\">Workshop") || !strings.Contains(body, ">entfällt</span>") {
+		t.Errorf("expected the cancelled event to be marked on its card, got %q", body)
+	}
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+const (
+	closureCalendar     = "closures"
+	closureModeAnnotate = "annotate"
+	closureModeHide     = "hide"
+)
+
+// ClosureConfig configures holidays and studio closures. Closures come from an iCal source,
+// from explicit date ranges or both. Mode is "annotate" (default) to mark overlapping events
+// as cancelled or "hide" to drop them.
+type ClosureConfig struct {
+	Source *SourceConfig  `json:"source"`
+	Ranges []ClosureRange `json:"ranges"`
+	Mode   string         `json:"mode"`
+}
+
+// ClosureRange is a closure given as inclusive dates in YYYY-MM-DD format, read as days in
+// the display time zone.
+type ClosureRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Title string `json:"title"`
+}
+
+// closure is a period in which no classes take place; end is exclusive.
+type closure struct {
+	title string
+	start time.Time
+	end   time.Time
+}
+
+// parseRanges converts the configured date ranges into closures.
+func (c ClosureConfig) parseRanges() ([]closure, error) {
+	var closures []closure
+	for i, r := range c.Ranges {
+		start, err := time.ParseInLocation("2006-01-02", r.Start, displayLocation)
+		if err != nil {
+			return nil, fmt.Errorf("closure %d: invalid start %q", i, r.Start)
+		}
+		end := start
+		if r.End != "" {
+			if end, err = time.ParseInLocation("2006-01-02", r.End, displayLocation); err != nil {
+				return nil, fmt.Errorf("closure %d: invalid end %q", i, r.End)
+			}
+		}
+		if end.Before(start) {
+			return nil, fmt.Errorf("closure %d: end before start", i)
+		}
+		closures = append(closures, closure{title: r.Title, start: start, end: end.AddDate(0, 0, 1)})
+	}
+	return closures, nil
+}
+
+// loadClosures returns the configured closures that have not ended before now.
+func loadClosures(now time.Time) []closure {
+	closures, _ := config.Closures.parseRanges()
+	if src := config.Closures.Source; src != nil {
+		cal, err := fetchSource(*src)
+		if err != nil {
+			slog.Error("load closures", "err", err)
+		} else {
+			closures = append(closures, parseClosures(cal)...)
+		}
+	}
+	active := closures[:0]
+	for _, c := range closures {
+		if c.end.After(now) {
+			active = append(active, c)
+		}
+	}
+	return active
+}
+
+// parseClosures reads closures from the events of an iCal calendar. All-day closures cover
+// whole days in the display time zone.
+func parseClosures(cal *ical.Calendar) []closure {
+	var closures []closure
+	for _, e := range cal.Events() {
+		var c closure
+		if prop := e.GetProperty(ical.ComponentPropertyDtStart); prop != nil {
+			c.start = closureTime(prop)
+		}
+		if prop := e.GetProperty(ical.ComponentPropertyDtEnd); prop != nil {
+			c.end = closureTime(prop)
+		}
+		if prop := e.GetProperty(ical.ComponentPropertySummary); prop != nil {
+			c.title = prop.Value
+		}
+		if c.start.IsZero() {
+			continue
+		}
+		if !c.end.After(c.start) {
+			c.end = c.start.AddDate(0, 0, 1)
+		}
+		closures = append(closures, c)
+	}
+	return closures
+}
+
+// closureTime parses a DTSTART or DTEND of a closure; dates are midnight in the display time zone.
+func closureTime(prop *ical.IANAProperty) time.Time {
+	t := parseEventTime(prop)
+	if isDateValue(prop) {
+		return dateIn(t, displayLocation)
+	}
+	return t
+}
+
+// applyClosures hides or annotates events overlapping a closure and adds each closure
+// as a banner entry.
+func applyClosures(events []CalendarEvent, closures []closure, mode string) []CalendarEvent {
+	if len(closures) == 0 {
+		return events
+	}
+	result := make([]CalendarEvent, 0, len(events)+len(closures))
+	for _, e := range events {
+		c, ok := overlappingClosure(e, closures)
+		if !ok {
+			result = append(result, e)
+			continue
+		}
+		if mode == closureModeHide {
+			continue
+		}
+		e.Cancelled = true
+		e.CancelReason = c.title
+		result = append(result, e)
+	}
+	for _, c := range closures {
+		result = append(result, closureEvent(c))
+	}
+	return result
+}
+
+// overlappingClosure returns the first closure overlapping the event.
+func overlappingClosure(e CalendarEvent, closures []closure) (closure, bool) {
+	end := e.End
+	if end.IsZero() {
+		end = e.Start
+	}
+	for _, c := range closures {
+		if !end.After(e.Start) {
+			if !e.Start.Before(c.start) && e.Start.Before(c.end) {
+				return c, true
+			}
+			continue
+		}
+		if e.Start.Before(c.end) && end.After(c.start) {
+			return c, true
+		}
+	}
+	return closure{}, false
+}
+
+// closureEvent turns a closure into an all-day banner entry of the event list.
+func closureEvent(c closure) CalendarEvent {
+	return CalendarEvent{
+		UID:       "closure-" + c.start.Format("20060102") + "-" + c.end.Format("20060102"),
+		Summary:   c.title,
+		Start:     c.start,
+		End:       c.end,
+		AllDay:    true,
+		Calendar:  closureCalendar,
+		IsClosure: true,
+	}
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+func TestClosureConfigParseRanges(t *testing.T) {
+	cfg := ClosureConfig{Ranges: []ClosureRange{{Start: "2025-07-01", End: "2025-07-14", Title: "Sommerferien"}, {Start: "2025-10-03", Title: "Feiertag"}}}
+	closures, err := cfg.parseRanges()
+	if err != nil {
+		t.Fatalf("parseRanges: %v", err)
+	}
+	if !closures[0].end.Equal(time.Date(2025, 7, 15, 0, 0, 0, 0, displayLocation)) {
+		t.Errorf("expected inclusive end date, got %v", closures[0].end)
+	}
+	if !closures[1].end.Equal(closures[1].start.AddDate(0, 0, 1)) {
+		t.Errorf("expected single day closure, got %v - %v", closures[1].start, closures[1].end)
+	}
+	early := testEvent("early", "Frühkurs", "", time.Date(2025, 7, 1, 0, 30, 0, 0, displayLocation))
+	if _, ok := overlappingClosure(early, closures); !ok {
+		t.Errorf("expected a class at 00:30 on the first day to be closed, closure starts %v", closures[0].start)
+	}
+	if _, err := (ClosureConfig{Ranges: []ClosureRange{{Start: "2025-07-14", End: "2025-07-01"}}}).parseRanges(); err == nil {
+		t.Error("expected error for end before start")
+	}
+}
+
+func TestApplyClosures(t *testing.T) {
+	closures := []closure{{title: "Sommerferien", start: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), end: time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)}}
+	events := []CalendarEvent{
+		testEvent("in", "Kurs im Juli", "", time.Date(2025, 7, 7, 18, 0, 0, 0, time.UTC)),
+		testEvent("out", "Kurs im August", "", time.Date(2025, 8, 4, 18, 0, 0, 0, time.UTC)),
+		testEvent("edge", "Kurs am Vorabend", "", time.Date(2025, 6, 30, 23, 0, 0, 0, time.UTC)),
+	}
+
+	annotated := applyClosures(events, closures, closureModeAnnotate)
+	if len(annotated) != 4 {
+		t.Fatalf("expected 3 events plus banner, got %d", len(annotated))
+	}
+	if !annotated[0].Cancelled || annotated[0].CancelReason != "Sommerferien" {
+		t.Errorf("expected overlapping event to be cancelled, got %+v", annotated[0])
+	}
+	if annotated[1].Cancelled || annotated[2].Cancelled {
+		t.Error("expected events outside the closure to stay")
+	}
+	if !annotated[3].IsClosure || !annotated[3].AllDay || !annotated[3].End.Equal(closures[0].end) {
+		t.Errorf("unexpected closure banner %+v", annotated[3])
+	}
+
+	hidden := applyClosures(events, closures, closureModeHide)
+	if len(hidden) != 3 || hidden[0].UID != "out" {
+		t.Errorf("expected overlapping event to be hidden, got %+v", hidden)
+	}
+}
+
+func TestParseClosuresFromICS(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:h1\r\nSUMMARY:Herbstferien\r\nDTSTART;VALUE=DATE:20251020\r\nDTEND;VALUE=DATE:20251101\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	cal, err := ical.ParseCalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	closures := parseClosures(cal)
+	if len(closures) != 1 || closures[0].title != "Herbstferien" || closures[0].end.Day() != 1 {
+		t.Errorf("unexpected closures %+v", closures)
+	}
+}
+
+func TestClosuresInAPIOutputs(t *testing.T) {
+	start := time.Now().Add(72 * time.Hour).Truncate(24 * time.Hour)
+	cal, err := ical.ParseCalendar(strings.NewReader(icsWithEvent("k1", "Kurs", start.Add(18*time.Hour))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calendarURLs = map[string]string{"sonderkurse": "webcal://unused"}
+	registerEventSource("sonderkurse", &memorySource{cal: cal})
+	defer delete(eventSources, "sonderkurse")
+	config = Config{Closures: ClosureConfig{Ranges: []ClosureRange{{Start: start.Format("2006-01-02"), Title: "Studio geschlossen"}}}}
+	defer func() { config = Config{} }()
+
+	w := httptest.NewRecorder()
+	eventsAPIHandler(w, httptest.NewRequest("GET", "/api/events?calendar=sonderkurse", nil))
+	var events []APIEvent
+	if err := json.NewDecoder(w.Body).Decode(&events); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(events) != 2 || !events[0].Closure || !events[1].Cancelled {
+		t.Errorf("expected closure banner and cancelled event, got %+v", events)
+	}
+
+	// Subscribers get the closures through the link of the calendar page.
+	subscribe := strings.Replace(calendarSubscribeURL("sonderkurse", "de"), "webcal://", "https://", 1)
+	w = httptest.NewRecorder()
+	icsHandler(w, httptest.NewRequest("GET", subscribe, nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "SUMMARY:Entfällt: Kurs") || !strings.Contains(body, "STATUS:CANCELLED") {
+		t.Errorf("expected cancelled event in ICS output, got %q", body)
+	}
+	if !strings.Contains(body, "DTSTART;VALUE=DATE:"+start.Format("20060102")) {
+		t.Errorf("expected all-day closure in ICS output, got %q", body)
+	}
+}
+
+func TestClosuresCancelRecurringOccurrences(t *testing.T) {
+	calendarURLs = map[string]string{"wochenkurse": ""}
+	useTestCalendar(t, "wochenkurse", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+		"BEGIN:VEVENT\r\nUID:kurs\r\nSUMMARY:Anfänger\r\nDTSTART:20250106T180000Z\r\nDTEND:20250106T193000Z\r\nRRULE:FREQ=WEEKLY\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+	config = Config{Closures: ClosureConfig{Ranges: []ClosureRange{{Start: "2025-07-14", End: "2025-07-27", Title: "Sommerferien"}}}}
+	defer func() { config = Config{} }()
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	cancelled := make(map[int]string)
+	for _, e := range fetchCalendarEvents([]string{"wochenkurse"}, now) {
+		if e.UID == "kurs" && e.Start.Month() == time.July {
+			cancelled[e.Start.Day()] = e.CancelReason
+		}
+	}
+	want := map[int]string{7: "", 14: "Sommerferien", 21: "Sommerferien", 28: ""}
+	for day, reason := range want {
+		if got, ok := cancelled[day]; !ok || got != reason {
+			t.Errorf("July %d: expected occurrence with cancel reason %q, got %q (present %v)", day, reason, got, ok)
+		}
+	}
+
+	config.Closures.Mode = closureModeHide
+	for _, e := range fetchCalendarEvents([]string{"wochenkurse"}, now) {
+		if e.UID == "kurs" && (e.Start.Day() == 14 || e.Start.Day() == 21) && e.Start.Month() == time.July {
+			t.Errorf("expected occurrence on %s to be hidden", e.Start.Format("2006-01-02"))
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config holds the optional settings read from the JSON file given with --config.
+type Config struct {
+	// BaseURL is the public address of the site, used for absolute links in feeds and
+	// permalinks; defaultBaseURL if empty.
+	BaseURL    string          `json:"baseURL"`
+	Webhooks   []WebhookConfig `json:"webhooks"`
+	WebhookLog string          `json:"webhookLog"`
+	Digest     DigestConfig    `json:"digest"`
+	Home       HomeConfig      `json:"home"`
+	Embed      EmbedConfig     `json:"embed"`
+	News       NewsConfig      `json:"news"`
+	Conflicts  ConflictConfig  `json:"conflicts"`
+	// Sources overrides where calendars (and the news calendar) load their events from.
+	Sources  map[string]SourceConfig `json:"sources"`
+	Closures ClosureConfig           `json:"closures"`
+	// CapacityFile is a JSON file with capacity and booking counts per event UID.
+	CapacityFile string `json:"capacityFile"`
+	// TimeZone is the IANA name of the zone event times are displayed in, Europe/Berlin if empty.
+	TimeZone string `json:"timeZone"`
+	// Alarms sets per calendar how many minutes before each event the served iCal feed
+	// reminds subscribers unless the alarm query parameter is given.
+	Alarms map[string]int `json:"alarms"`
+}
+
+// maxAlarmMinutes bounds reminders to one week before the event.
+const maxAlarmMinutes = 7 * 24 * 60
+
+// WebhookConfig describes one outgoing webhook endpoint.
+// Empty Calendars or Kinds mean that all changes are delivered.
+type WebhookConfig struct {
+	URL       string       `json:"url"`
+	Secret    string       `json:"secret"`
+	Calendars []string     `json:"calendars"`
+	Kinds     []ChangeKind `json:"kinds"`
+}
+
+var config Config
+
+// defaultBaseURL is the public address of the site unless the config sets baseURL.
+const defaultBaseURL = "https://yang-taichi.com"
+
+// siteBaseURL returns the public address of the site without a trailing slash.
+func siteBaseURL() string {
+	if config.BaseURL != "" {
+		return strings.TrimSuffix(config.BaseURL, "/")
+	}
+	return defaultBaseURL
+}
+
+// LoadConfig reads the JSON configuration file at path. An empty path keeps the defaults.
+func LoadConfig(path string) error {
+	if path == "" {
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	for i, wh := range cfg.Webhooks {
+		if wh.URL == "" {
+			return fmt.Errorf("webhook %d: missing url", i)
+		}
+	}
+	switch cfg.Digest.SMTP.TLS {
+	case "", "starttls", "tls", "none":
+	default:
+		return fmt.Errorf("smtp: unknown tls mode %q", cfg.Digest.SMTP.TLS)
+	}
+	for i, sub := range cfg.Digest.Subscribers {
+		if sub.Email == "" {
+			return fmt.Errorf("digest subscriber %d: missing email", i)
+		}
+	}
+	sources := make(map[string]EventSource, len(cfg.Sources))
+	for name, src := range cfg.Sources {
+		if src.URL == "" {
+			return fmt.Errorf("source %s: missing url", name)
+		}
+		if sources[name], err = newEventSource(src); err != nil {
+			return fmt.Errorf("source %s: %w", name, err)
+		}
+	}
+	if src := cfg.Closures.Source; src != nil {
+		if _, err := newEventSource(*src); err != nil {
+			return fmt.Errorf("closures source: %w", err)
+		}
+	}
+	if _, err := cfg.Closures.parseRanges(); err != nil {
+		return err
+	}
+	switch cfg.Closures.Mode {
+	case "", closureModeAnnotate, closureModeHide:
+	default:
+		return fmt.Errorf("closures: unknown mode %q", cfg.Closures.Mode)
+	}
+	for name, minutes := range cfg.Alarms {
+		if minutes < 0 || minutes > maxAlarmMinutes {
+			return fmt.Errorf("alarm %s: %d minutes out of range", name, minutes)
+		}
+	}
+	tz := cfg.TimeZone
+	if tz == "" {
+		tz = defaultTimeZone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return fmt.Errorf("time zone %q: %w", cfg.TimeZone, err)
+	}
+	config = cfg
+	displayLocation = loc
+	applySources(sources)
+	capacityOverrides.setPath(cfg.CapacityFile)
+	newsPosts.setDir(cfg.News.Dir)
+	newsImages.setDir(cfg.News.ImageCache)
+	slog.Info("Loaded config", "path", path, "webhooks", len(cfg.Webhooks), "sources", len(cfg.Sources))
+	return nil
+}
+
+// applySources registers the configured event sources. Calendars that are not known yet
+// are added without a public subscription URL and in a neutral colour.
+func applySources(sources map[string]EventSource) {
+	for name, src := range sources {
+		registerEventSource(name, src)
+		if _, isNews := newsURLs[name]; isNews {
+			continue
+		}
+		if _, ok := calendarURLs[name]; !ok {
+			calendarURLs[name] = ""
+		}
+		if _, ok := calendarColors[name]; !ok {
+			calendarColors[name] = defaultCalendarColor
+			calendarBtnClasses[name] = defaultCalendarBtnClass
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+// ConflictConfig configures the scheduling conflict check. InstructorProperty names the event
+// property holding the instructor, e.g. "X-YTC-INSTRUCTOR"; ORGANIZER is used if it is empty.
+type ConflictConfig struct {
+	InstructorProperty string `json:"instructorProperty"`
+}
+
+// Conflict reasons.
+const (
+	ConflictLocation   = "location"
+	ConflictInstructor = "instructor"
+)
+
+// Conflict is a pair of overlapping events sharing a location or an instructor.
+type Conflict struct {
+	Reason string        `json:"reason"`
+	Shared string        `json:"shared"`
+	First  ConflictEvent `json:"first"`
+	Second ConflictEvent `json:"second"`
+}
+
+// ConflictEvent identifies one event of a conflict.
+type ConflictEvent struct {
+	Calendar     string    `json:"calendar"`
+	UID          string    `json:"uid"`
+	RecurrenceID string    `json:"recurrenceId,omitempty"`
+	Summary      string    `json:"summary"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+}
+
+// parseInstructor returns the instructor of an event from the configured property or ORGANIZER.
+func parseInstructor(e *ical.VEvent) string {
+	if name := config.Conflicts.InstructorProperty; name != "" {
+		if prop := e.GetProperty(ical.ComponentProperty(strings.ToUpper(name))); prop != nil {
+			return strings.TrimSpace(prop.Value)
+		}
+		return ""
+	}
+	prop := e.GetProperty(ical.ComponentPropertyOrganizer)
+	if prop == nil {
+		return ""
+	}
+	if cn := prop.ICalParameters[string(ical.ParameterCn)]; len(cn) > 0 && cn[0] != "" {
+		return strings.Trim(cn[0], `"`)
+	}
+	value := prop.Value
+	if len(value) > len("mailto:") && strings.EqualFold(value[:len("mailto:")], "mailto:") {
+		value = value[len("mailto:"):]
+	}
+	return strings.TrimSpace(value)
+}
+
+// loadExpandedEvents loads the given calendars and expands their recurring events between from and until.
+func loadExpandedEvents(calendars []string, from, until time.Time) ([]CalendarEvent, error) {
+	var (
+		events []CalendarEvent
+		errs   []error
+	)
+	for _, name := range calendars {
+		cal, err := loadCalendar(name, calendarURLs[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		events = append(events, expandCalendar(cal, name, from, until)...)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+	return events, errors.Join(errs...)
+}
+
+// FindConflicts loads all calendars and reports overlapping events within the next days.
+func FindConflicts(days int) ([]Conflict, error) {
+	calendars := make([]string, 0, len(calendarURLs))
+	for name := range calendarURLs {
+		calendars = append(calendars, name)
+	}
+	sort.Strings(calendars)
+	now := clock()
+	events, err := loadExpandedEvents(calendars, now, now.AddDate(0, 0, days))
+	return findConflicts(events), err
+}
+
+// findConflicts returns the pairs of overlapping events that share a location or an instructor.
+// Events must be sorted by start; cancelled and all-day events never conflict.
+func findConflicts(events []CalendarEvent) []Conflict {
+	var conflicts []Conflict
+	for i, a := range events {
+		if !schedulable(a) {
+			continue
+		}
+		for _, b := range events[i+1:] {
+			if !b.Start.Before(a.End) {
+				break
+			}
+			if !schedulable(b) || a.UID == b.UID && a.RecurrenceID == b.RecurrenceID {
+				continue
+			}
+			if shared, ok := sameValue(a.Location, b.Location); ok {
+				conflicts = append(conflicts, newConflict(ConflictLocation, shared, a, b))
+			}
+			if shared, ok := sameValue(a.Instructor, b.Instructor); ok {
+				conflicts = append(conflicts, newConflict(ConflictInstructor, shared, a, b))
+			}
+		}
+	}
+	return conflicts
+}
+
+func schedulable(e CalendarEvent) bool {
+	return !e.AllDay && !e.Cancelled && e.End.After(e.Start)
+}
+
+func sameValue(a, b string) (string, bool) {
+	a = strings.TrimSpace(a)
+	return a, a != "" && strings.EqualFold(a, strings.TrimSpace(b))
+}
+
+func newConflict(reason, shared string, a, b CalendarEvent) Conflict {
+	event := func(e CalendarEvent) ConflictEvent {
+		return ConflictEvent{Calendar: e.Calendar, UID: e.UID, RecurrenceID: e.RecurrenceID, Summary: e.Summary, Start: e.Start, End: e.End}
+	}
+	return Conflict{Reason: reason, Shared: shared, First: event(a), Second: event(b)}
+}
+
+// WriteConflicts writes the conflicts as a table or, with format "json", as a JSON array.
+func WriteConflicts(w io.Writer, conflicts []Conflict, format string) error {
+	if format == "json" {
+		if conflicts == nil {
+			conflicts = []Conflict{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(conflicts)
+	}
+	if len(conflicts) == 0 {
+		_, err := fmt.Fprintln(w, "No conflicts found.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REASON\tSHARED\tFIRST\tSECOND")
+	for _, c := range conflicts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Reason, c.Shared, describeConflictEvent(c.First), describeConflictEvent(c.Second))
+	}
+	return tw.Flush()
+}
+
+func describeConflictEvent(e ConflictEvent) string {
+	start := e.Start.In(displayLocation)
+	return fmt.Sprintf("%s–%s %s (%s)", start.Format("2006-01-02 15:04"), e.End.In(displayLocation).Format("15:04"), e.Summary, e.Calendar)
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+func TestFindConflicts(t *testing.T) {
+	base := time.Date(2025, 5, 12, 18, 0, 0, 0, displayLocation)
+	events := []CalendarEvent{
+		{UID: "a", Calendar: "wochenkurse", Summary: "Anfänger", Location: "Halle 1", Instructor: "Stephan", Start: base, End: base.Add(90 * time.Minute)},
+		{UID: "b", Calendar: "sonderkurse", Summary: "Workshop", Location: "halle 1 ", Instructor: "Anna", Start: base.Add(time.Hour), End: base.Add(2 * time.Hour)},
+		{UID: "c", Calendar: "sonderkurse", Summary: "Online", Location: "Zoom", Instructor: "Stephan", Start: base.Add(80 * time.Minute), End: base.Add(3 * time.Hour)},
+		{UID: "d", Calendar: "ferienkurse", Summary: "Abgesagt", Location: "Halle 1", Start: base, End: base.Add(time.Hour), Cancelled: true},
+		{UID: "e", Calendar: "wochenkurse", Summary: "Später", Location: "Halle 1", Instructor: "Stephan", Start: base.Add(3 * time.Hour), End: base.Add(4 * time.Hour)},
+	}
+	conflicts := findConflicts(events)
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", conflicts)
+	}
+	if conflicts[0].Reason != ConflictLocation || conflicts[0].Second.UID != "b" {
+		t.Errorf("expected location conflict with b, got %+v", conflicts[0])
+	}
+	if conflicts[1].Reason != ConflictInstructor || conflicts[1].Shared != "Stephan" || conflicts[1].Second.UID != "c" {
+		t.Errorf("expected instructor conflict with c, got %+v", conflicts[1])
+	}
+
+	var table bytes.Buffer
+	if err := WriteConflicts(&table, conflicts, "table"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), "REASON") || !strings.Contains(table.String(), "2025-05-12 18:00–19:30 Anfänger (wochenkurse)") {
+		t.Errorf("unexpected table %q", table.String())
+	}
+	var out bytes.Buffer
+	if err := WriteConflicts(&out, nil, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []Conflict
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || decoded == nil {
+		t.Errorf("expected empty JSON array, got %q", out.String())
+	}
+}
+
+func TestParseInstructorProperty(t *testing.T) {
+	event := ical.NewEvent("i")
+	event.SetProperty(ical.ComponentPropertyOrganizer, "mailto:info@example.org")
+	if got := parseInstructor(event); got != "info@example.org" {
+		t.Errorf("expected organizer address, got %q", got)
+	}
+	event.SetProperty(ical.ComponentProperty("X-YTC-INSTRUCTOR"), "Anna")
+	config = Config{Conflicts: ConflictConfig{InstructorProperty: "x-ytc-instructor"}}
+	defer func() { config = Config{} }()
+	if got := parseInstructor(event); got != "Anna" {
+		t.Errorf("expected configured property, got %q", got)
+	}
+}
@@ -0,0 +1,323 @@
+package app
+
+import (
+	"bytes"
+	"crypto/tls"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed static/templates/*/email/*
+var emailTemplatesFS embed.FS
+
+const defaultDigestDays = 7
+
+// DigestConfig configures the email digest of upcoming events.
+type DigestConfig struct {
+	Days        int                `json:"days"`
+	Calendars   []string           `json:"calendars"`
+	BaseURL     string             `json:"baseURL"`
+	Subscribers []DigestSubscriber `json:"subscribers"`
+	SMTP        SMTPConfig         `json:"smtp"`
+}
+
+// DigestSubscriber is a recipient of the digest in the given language.
+type DigestSubscriber struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Lang  string `json:"lang"`
+}
+
+// SMTPConfig describes the outgoing mail server. TLS is one of "starttls" (default), "tls" or "none".
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	TLS      string `json:"tls"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+}
+
+// DigestData is passed to the digest email templates.
+type DigestData struct {
+	Lang    string
+	Name    string
+	Days    int
+	BaseURL string
+	Events  []CalendarEvent
+}
+
+// digestMessage is a rendered digest email for one subscriber.
+type digestMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// digestDays returns the configured digest period, falling back to a week.
+func (c DigestConfig) digestDays() int {
+	if c.Days <= 0 {
+		return defaultDigestDays
+	}
+	return c.Days
+}
+
+// baseURL returns the address links in the digest point to, falling back to the site's.
+func (c DigestConfig) baseURL() string {
+	if c.BaseURL == "" {
+		return siteBaseURL()
+	}
+	return strings.TrimSuffix(c.BaseURL, "/")
+}
+
+// digestCalendars returns the configured digest calendars, falling back to all calendars.
+func (c DigestConfig) digestCalendars() []string {
+	if len(c.Calendars) > 0 {
+		return c.Calendars
+	}
+	cals := make([]string, 0, len(calendarURLs))
+	for cal := range calendarURLs {
+		cals = append(cals, cal)
+	}
+	sort.Strings(cals)
+	return cals
+}
+
+// fetchUpcomingEvents returns the events of the given calendars starting before until, sorted by
+// start. Closures and cancelled events are left out, since the digest lists what takes place.
+func fetchUpcomingEvents(calendars []string, now, until time.Time) []CalendarEvent {
+	var events []CalendarEvent
+	for _, e := range fetchCalendarEvents(calendars, now) {
+		if e.Start.Before(until) && !e.IsClosure && !e.Cancelled {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// renderDigest renders the localized text and HTML parts of the digest for one subscriber.
+func renderDigest(sub DigestSubscriber, cfg DigestConfig, events []CalendarEvent) (digestMessage, error) {
+	lang := sub.Lang
+	if !isSupportedLang(lang) {
+		lang = defaultLang
+	}
+	pattern := "static/templates/" + lang + "/email/"
+	textTmpl, err := texttemplate.New("digest").Funcs(formatFuncs(lang, displayLocation)).ParseFS(emailTemplatesFS, pattern+"digest.txt")
+	if err != nil {
+		return digestMessage{}, fmt.Errorf("parse text digest template: %w", err)
+	}
+	htmlTmpl, err := htmltemplate.New("digest").Funcs(formatFuncs(lang, displayLocation)).ParseFS(emailTemplatesFS, pattern+"digest.txt", pattern+"digest.html")
+	if err != nil {
+		return digestMessage{}, fmt.Errorf("parse html digest template: %w", err)
+	}
+	data := DigestData{
+		Lang:    lang,
+		Name:    sub.Name,
+		Days:    cfg.digestDays(),
+		BaseURL: cfg.baseURL(),
+		Events:  events,
+	}
+	var subject, text, html bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return digestMessage{}, err
+	}
+	if err := textTmpl.ExecuteTemplate(&text, "digest.txt", data); err != nil {
+		return digestMessage{}, err
+	}
+	if err := htmlTmpl.ExecuteTemplate(&html, "digest.html", data); err != nil {
+		return digestMessage{}, err
+	}
+	return digestMessage{
+		To:      sub.Email,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// bytes encodes the message as a multipart/alternative MIME email.
+func (m digestMessage) bytes(from string, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", m.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// PreviewDigest renders the digest for a sample subscriber in lang and writes the text or HTML part to w.
+func PreviewDigest(w io.Writer, lang, format string) error {
+	cfg := config.Digest
+	now := clock()
+	events := fetchUpcomingEvents(cfg.digestCalendars(), now, now.AddDate(0, 0, cfg.digestDays()))
+	msg, err := renderDigest(DigestSubscriber{Lang: lang}, cfg, events)
+	if err != nil {
+		return err
+	}
+	if format == "html" {
+		_, err = io.WriteString(w, msg.HTML)
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Subject: %s\n\n%s", msg.Subject, msg.Text)
+	return err
+}
+
+// SendDigest renders the digest for every subscriber and sends it over SMTP.
+// If dryRunDir is set, the emails are written there as .eml files instead.
+func SendDigest(dryRunDir string) error {
+	cfg := config.Digest
+	if len(cfg.Subscribers) == 0 {
+		return fmt.Errorf("no digest subscribers configured")
+	}
+	if dryRunDir == "" && cfg.SMTP.Host == "" {
+		return fmt.Errorf("no SMTP host configured")
+	}
+	if dryRunDir != "" {
+		if err := os.MkdirAll(dryRunDir, 0755); err != nil {
+			return err
+		}
+	}
+	now := clock()
+	events := fetchUpcomingEvents(cfg.digestCalendars(), now, now.AddDate(0, 0, cfg.digestDays()))
+	slog.Info("Sending digest", "subscribers", len(cfg.Subscribers), "events", len(events), "dryRun", dryRunDir != "")
+
+	var failed int
+	for _, sub := range cfg.Subscribers {
+		msg, err := renderDigest(sub, cfg, events)
+		if err != nil {
+			return err
+		}
+		raw, err := msg.bytes(cfg.SMTP.From, now)
+		if err != nil {
+			return err
+		}
+		if dryRunDir != "" {
+			path := filepath.Join(dryRunDir, sanitizeFileName(sub.Email)+".eml")
+			if err := os.WriteFile(path, raw, 0644); err != nil {
+				return err
+			}
+			slog.Info("Wrote digest", "to", sub.Email, "path", path)
+			continue
+		}
+		if err := sendMail(cfg.SMTP, sub.Email, raw); err != nil {
+			slog.Error("Failed to send digest", "to", sub.Email, "err", err)
+			failed++
+			continue
+		}
+		slog.Info("Sent digest", "to", sub.Email)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d of %d digests", failed, len(cfg.Subscribers))
+	}
+	return nil
+}
+
+// sendMail delivers raw to a single recipient using the configured SMTP server.
+func sendMail(cfg SMTPConfig, to string, raw []byte) error {
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+		if cfg.TLS == "tls" {
+			port = 465
+		}
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+
+	var client *smtp.Client
+	if cfg.TLS == "tls" {
+		conn, err := tls.Dial("tcp", addr, tlsConfig)
+		if err != nil {
+			return err
+		}
+		if client, err = smtp.NewClient(conn, cfg.Host); err != nil {
+			conn.Close()
+			return err
+		}
+	} else {
+		var err error
+		if client, err = smtp.Dial(addr); err != nil {
+			return err
+		}
+	}
+	defer client.Close()
+
+	if cfg.TLS == "" || cfg.TLS == "starttls" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(cfg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// sanitizeFileName replaces characters that are unsafe in file names.
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderDigest(t *testing.T) {
+	events := []CalendarEvent{
+		{Summary: "Anfänger <Kurs>", Start: time.Date(2025, 5, 12, 18, 0, 0, 0, time.UTC), End: time.Date(2025, 5, 12, 19, 30, 0, 0, time.UTC), Location: "Halle 1"},
+	}
+	cfg := DigestConfig{Days: 14, BaseURL: "https://example.org/"}
+	msg, err := renderDigest(DigestSubscriber{Email: "a@example.org", Name: "Anna", Lang: "de"}, cfg, events)
+	if err != nil {
+		t.Fatalf("renderDigest: %v", err)
+	}
+	if msg.Subject != "Yang Tai Chi Hamburg: Termine der nächsten 14 Tage" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "Hallo Anna") || !strings.Contains(msg.Text, "Anfänger <Kurs>") || !strings.Contains(msg.Text, "Ort: Halle 1") {
+		t.Errorf("unexpected text part %q", msg.Text)
+	}
+	if !strings.Contains(msg.HTML, "Anfänger &lt;Kurs&gt;") || !strings.Contains(msg.HTML, `href="https://example.org/calendar?lang=de"`) {
+		t.Errorf("unexpected html part %q", msg.HTML)
+	}
+
+	en, err := renderDigest(DigestSubscriber{Email: "b@example.org", Lang: "fr"}, cfg, nil)
+	if err != nil {
+		t.Fatalf("renderDigest: %v", err)
+	}
+	if !strings.Contains(en.Text, "keine Termine") {
+		t.Errorf("expected fallback to default language without events, got %q", en.Text)
+	}
+}
+
+func TestDigestMessageBytes(t *testing.T) {
+	msg := digestMessage{To: "a@example.org", Subject: "Termine für Mai", Text: "text", HTML: "<p>html</p>"}
+	raw, err := msg.bytes("studio@example.org", time.Date(2025, 5, 12, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("bytes: %v", err)
+	}
+	s := string(raw)
+	for _, want := range []string{
+		"From: studio@example.org\r\n",
+		"To: a@example.org\r\n",
+		"Subject: =?utf-8?q?Termine_f=C3=BCr_Mai?=\r\n",
+		"Content-Type: multipart/alternative; boundary=",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Type: text/html; charset=utf-8",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected message to contain %q", want)
+		}
+	}
+}
+
+func TestSendDigestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	config = Config{Digest: DigestConfig{
+		Calendars:   []string{"missing"},
+		Subscribers: []DigestSubscriber{{Email: "a@example.org", Lang: "en"}, {Email: "b@example.org", Lang: "de"}},
+		SMTP:        SMTPConfig{From: "studio@example.org"},
+	}}
+	defer func() { config = Config{} }()
+	urls := calendarURLs
+	calendarURLs = map[string]string{}
+	t.Cleanup(func() { calendarURLs = urls })
+	if err := SendDigest(dir); err != nil {
+		t.Fatalf("SendDigest: %v", err)
+	}
+	for _, name := range []string{"a@example.org.eml", "b@example.org.eml"} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected %s to be written: %v", name, err)
+		}
+		if !strings.Contains(string(b), "From: studio@example.org") {
+			t.Errorf("%s missing From header", name)
+		}
+	}
+}
+
+func TestFetchUpcomingEventsSkipsClosuresAndCancellations(t *testing.T) {
+	now := time.Date(2025, 7, 7, 8, 0, 0, 0, time.UTC)
+	calendarURLs = map[string]string{"wochenkurse": ""}
+	useTestCalendar(t, "wochenkurse", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+		"BEGIN:VEVENT\r\nUID:mo\r\nSUMMARY:Anfänger\r\nDTSTART:20250707T180000Z\r\nDTEND:20250707T190000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:di\r\nSUMMARY:Abgesagt\r\nSTATUS:CANCELLED\r\nDTSTART:20250708T180000Z\r\nDTEND:20250708T190000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:mi\r\nSUMMARY:Ferien\r\nDTSTART:20250709T180000Z\r\nDTEND:20250709T190000Z\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+	config = Config{Closures: ClosureConfig{Ranges: []ClosureRange{{Start: "2025-07-09", Title: "Sommerferien"}}}}
+	defer func() { config = Config{} }()
+
+	events := fetchUpcomingEvents([]string{"wochenkurse"}, now, now.AddDate(0, 0, 7))
+	if len(events) != 1 || events[0].UID != "mo" {
+		t.Errorf("expected only the class that takes place, got %+v", events)
+	}
+}
+
+func TestLoadConfigRejectsUnknownTLS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"digest": {"smtp": {"host": "smtp.example.org", "tls": "ssl"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), `"ssl"`) {
+		t.Errorf("expected an error for an unknown tls mode, got %v", err)
+	}
+}
@@ -64,9 +64,10 @@ func getDownloadFiles(downloadDir string) ([]DownloadFile, error) {
 func renderDownloadPage(w http.ResponseWriter, tmpl *template.Template, lang string, files []DownloadFile) {
 	slog.Info("Rendering download page", "lang", lang, "fileCount", len(files))
 	data := DownloadTemplateData{
-		Page:  "download",
-		Lang:  lang,
-		Files: files,
+		Page:   "download",
+		Lang:   lang,
+		Files:  files,
+		Banner: bannerNews(lang, clock()),
 	}
 	if err := tmpl.ExecuteTemplate(w, "download.html", data); err != nil {
 		slog.Error("Failed to render download template", "err", err)
@@ -0,0 +1,152 @@
+package app
+
+import (
+	_ "embed"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultEmbedLimit = 10
+	maxEmbedLimit     = 100
+)
+
+//go:embed static/embed/calendar.js
+var embedLoaderJS []byte
+
+// EmbedConfig lists the partner origins allowed to frame /embed/calendar and to load
+// events with the script loader, e.g. "https://partner.example". "*" allows every origin.
+type EmbedConfig struct {
+	FrameAncestors []string `json:"frameAncestors"`
+}
+
+// EmbedTheme holds the colors of the embedded schedule.
+type EmbedTheme struct {
+	Accent     string
+	Background string
+	Text       string
+}
+
+type EmbedTemplateData struct {
+	Page     string
+	Lang     string
+	Events   []CalendarEvent
+	Theme    EmbedTheme
+	Calendar string
+}
+
+var defaultEmbedTheme = EmbedTheme{Accent: "#0d6efd", Background: "#ffffff", Text: "#212529"}
+
+var cssColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// eventFilter restricts the events served to the iframe, the script loader and the JSON API.
+type eventFilter struct {
+	calendars []string
+	days      int
+	limit     int
+}
+
+// parseEventFilter reads the calendar, days and limit query parameters.
+func parseEventFilter(q url.Values, defaultLimit int) eventFilter {
+	f := eventFilter{limit: defaultLimit}
+	f.calendars, _ = getSelectedCalendars(q.Get("calendar"))
+	if days, err := strconv.Atoi(q.Get("days")); err == nil && days > 0 {
+		f.days = days
+	}
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit > 0 {
+		f.limit = min(limit, maxEmbedLimit)
+	}
+	return f
+}
+
+// apply drops the events starting after the filtered period and cuts the list to the limit.
+func (f eventFilter) apply(events []CalendarEvent, now time.Time) []CalendarEvent {
+	var result []CalendarEvent
+	for _, e := range events {
+		if f.limit > 0 && len(result) >= f.limit {
+			break
+		}
+		if f.days > 0 && !e.Start.Before(now.AddDate(0, 0, f.days)) {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// parseEmbedTheme reads the accent, bg and text colors, keeping the default for invalid values.
+func parseEmbedTheme(q url.Values) EmbedTheme {
+	theme := defaultEmbedTheme
+	for param, field := range map[string]*string{"accent": &theme.Accent, "bg": &theme.Background, "text": &theme.Text} {
+		if c := q.Get(param); cssColorPattern.MatchString(c) {
+			*field = c
+		}
+	}
+	return theme
+}
+
+// frameAncestors returns the Content-Security-Policy frame-ancestors directive for the configured origins.
+func (c EmbedConfig) frameAncestors() string {
+	return strings.TrimSpace("frame-ancestors 'self' " + strings.Join(c.FrameAncestors, " "))
+}
+
+// allowsOrigin reports whether origin may embed the schedule.
+func (c EmbedConfig) allowsOrigin(origin string) bool {
+	for _, o := range c.FrameAncestors {
+		if o == "*" || strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowEmbedOrigin lets allowed partner origins read the response from the browser.
+func allowEmbedOrigin(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" || !config.Embed.allowsOrigin(origin) {
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Add("Vary", "Origin")
+}
+
+// embedCalendarHandler renders the schedule without navigation for use in an iframe.
+func embedCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	tmpl, ok := templateFor(lang, getLocation(w, r))
+	if !ok {
+		slog.Error("template not found for language", "lang", lang)
+		http.Error(w, "Template not found", http.StatusInternalServerError)
+		return
+	}
+	q := r.URL.Query()
+	now := clock()
+	filter := parseEventFilter(q, defaultEmbedLimit)
+	data := EmbedTemplateData{
+		Page:     "embed",
+		Lang:     lang,
+		Events:   filter.apply(fetchCalendarEvents(filter.calendars, now), now),
+		Theme:    parseEmbedTheme(q),
+		Calendar: q.Get("calendar"),
+	}
+	w.Header().Set("Content-Security-Policy", config.Embed.frameAncestors())
+	slog.Debug("renderTemplate", "lang", lang, "page", "embed.html", "events", len(data.Events))
+	if err := tmpl.ExecuteTemplate(w, "embed.html", data); err != nil {
+		slog.Error("render template", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// embedLoaderHandler serves the script that renders the schedule into a partner page.
+func embedLoaderHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if _, err := w.Write(embedLoaderJS); err != nil {
+		slog.Error("write embed loader", "err", err)
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventFilter(t *testing.T) {
+	calendarURLs = map[string]string{"wochenkurse": "", "sonderkurse": ""}
+	f := parseEventFilter(url.Values{"calendar": {"sonderkurse,unknown"}, "days": {"7"}, "limit": {"500"}}, defaultEmbedLimit)
+	if len(f.calendars) != 1 || f.days != 7 || f.limit != maxEmbedLimit {
+		t.Errorf("unexpected filter %+v", f)
+	}
+
+	now := time.Date(2025, 5, 12, 8, 0, 0, 0, time.UTC)
+	events := []CalendarEvent{
+		{UID: "a", Start: now.Add(24 * time.Hour)},
+		{UID: "b", Start: now.Add(48 * time.Hour)},
+		{UID: "c", Start: now.AddDate(0, 0, 10)},
+	}
+	if got := (eventFilter{days: 7}).apply(events, now); len(got) != 2 {
+		t.Errorf("expected events within 7 days, got %+v", got)
+	}
+	if got := (eventFilter{limit: 1}).apply(events, now); len(got) != 1 || got[0].UID != "a" {
+		t.Errorf("expected first event only, got %+v", got)
+	}
+}
+
+func TestParseEmbedTheme(t *testing.T) {
+	theme := parseEmbedTheme(url.Values{"accent": {"#198754"}, "bg": {"red;}body{display:none"}})
+	if theme.Accent != "#198754" || theme.Background != defaultEmbedTheme.Background || theme.Text != defaultEmbedTheme.Text {
+		t.Errorf("unexpected theme %+v", theme)
+	}
+}
+
+func TestEmbedCalendarHandler(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	calendarURLs = map[string]string{"sonderkurse": ""}
+	useTestCalendar(t, "sonderkurse", icsWithEvent("s1", "Workshop", time.Now().Add(24*time.Hour)))
+	config = Config{Embed: EmbedConfig{FrameAncestors: []string{"https://partner.example"}}}
+	defer func() { config = Config{} }()
+
+	w := httptest.NewRecorder()
+	embedCalendarHandler(w, httptest.NewRequest("GET", "/embed/calendar?calendar=sonderkurse&accent=%23198754&lang=en", nil))
+	if got := w.Header().Get("Content-Security-Policy"); got != "frame-ancestors 'self' https://partner.example" {
+		t.Errorf("unexpected CSP %q", got)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Workshop") || !strings.Contains(body, "#198754") || strings.Contains(body, "navbar") {
+		t.Errorf("unexpected embed page %q", body)
+	}
+
+	r := httptest.NewRequest("GET", "/api/events?calendar=sonderkurse&limit=1", nil)
+	r.Header.Set("Origin", "https://partner.example")
+	w = httptest.NewRecorder()
+	eventsAPIHandler(w, r)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://partner.example" {
+		t.Errorf("expected CORS header for allowed origin, got %q", got)
+	}
+	r.Header.Set("Origin", "https://other.example")
+	w = httptest.NewRecorder()
+	eventsAPIHandler(w, r)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no CORS header for other origin, got %q", got)
+	}
+}
+
+func TestEmbedLoaderHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	embedLoaderHandler(w, httptest.NewRequest("GET", "/embed/calendar.js", nil))
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/javascript") || !strings.Contains(w.Body.String(), "/api/events?") {
+		t.Errorf("unexpected loader response %q", w.Body.String())
+	}
+}
@@ -0,0 +1,295 @@
+package app
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var feedTitles = map[string]string{
+	"de": "Yang Tai Chi Hamburg – Neuigkeiten",
+	"en": "Yang Tai Chi Hamburg – News",
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang    string      `xml:"xml:lang,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Lang      string    `xml:"xml:lang,attr,omitempty"`
+	ID        string    `xml:"id"`
+	Title     string    `xml:"title"`
+	Published string    `xml:"published"`
+	Updated   string    `xml:"updated"`
+	Link      atomLink  `xml:"link"`
+	Content   *atomText `xml:"content,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// jsonFeed is a JSON Feed 1.1 document, see https://www.jsonfeed.org/version/1.1/.
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Language    string           `json:"language"`
+	Authors     []jsonFeedAuthor `json:"authors"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	DatePublished string `json:"date_published"`
+	Language      string `json:"language,omitempty"`
+	Image         string `json:"image,omitempty"`
+}
+
+var (
+	feedURLPattern       = regexp.MustCompile(`https?://[^\s<>"]+[^\s<>".,;:!?)]`)
+	feedParagraphPattern = regexp.MustCompile(`\n\s*\n`)
+)
+
+// descriptionHTML renders a plain-text description as safe HTML: markup is escaped, blank lines
+// separate paragraphs, line breaks are kept and web addresses become links.
+func descriptionHTML(description string) string {
+	var b strings.Builder
+	for _, para := range feedParagraphPattern.Split(strings.ReplaceAll(strings.TrimSpace(description), "\r\n", "\n"), -1) {
+		if para == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		for i, line := range strings.Split(para, "\n") {
+			if i > 0 {
+				b.WriteString("<br>")
+			}
+			last := 0
+			for _, m := range feedURLPattern.FindAllStringIndex(line, -1) {
+				link := html.EscapeString(line[m[0]:m[1]])
+				b.WriteString(html.EscapeString(line[last:m[0]]))
+				b.WriteString(`<a href="` + link + `">` + link + `</a>`)
+				last = m[1]
+			}
+			b.WriteString(html.EscapeString(line[last:]))
+		}
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
+
+// feedNews returns the news items in lang that can appear in a feed: those that have been
+// published by now and have not expired.
+func feedNews(lang string, now time.Time) []NewsItem {
+	var items []NewsItem
+	for _, e := range activeNews(newsForLang(newsCache.get(), lang), now) {
+		if e.UID != "" && !e.Start.IsZero() && !e.Start.After(now) && !e.Cancelled {
+			items = append(items, e)
+		}
+	}
+	return items
+}
+
+// latestNewsStart returns the publication time of the newest item; items are not sorted by
+// it, as pinned ones come first.
+func latestNewsStart(items []NewsItem) time.Time {
+	var latest time.Time
+	for _, e := range items {
+		if e.Start.After(latest) {
+			latest = e.Start
+		}
+	}
+	return latest
+}
+
+// absoluteURL prefixes a site-relative path with base; empty paths stay empty.
+func absoluteURL(base, path string) string {
+	if path == "" {
+		return ""
+	}
+	return base + path
+}
+
+// newsItemID returns the ID of a news item in the Atom and JSON feeds, derived from its UID.
+func newsItemID(uid string) string {
+	return "urn:ytc:news:" + url.PathEscape(uid)
+}
+
+// rssHandler serves the news as an RSS 2.0 feed.
+func rssHandler(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	base := siteBaseURL()
+	items := feedNews(lang, clock())
+	feed := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       feedTitles[lang],
+			Link:        base + "/news?lang=" + lang,
+			Description: feedTitles[lang],
+			Language:    lang,
+			Self:        atomLink{Href: base + "/news.rss?lang=" + lang, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if latest := latestNewsStart(items); !latest.IsZero() {
+		feed.Channel.LastBuildDate = latest.Format(time.RFC1123Z)
+	}
+	for _, e := range items {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       e.Summary,
+			Link:        base + e.NewsPath() + "?lang=" + lang,
+			Description: newsContentHTML(e),
+			GUID:        rssGUID{Value: e.UID},
+			PubDate:     e.Start.Format(time.RFC1123Z),
+		})
+	}
+	slog.Debug("serve rss", "lang", lang, "items", len(items))
+	writeFeed(w, "application/rss+xml; charset=utf-8", feed)
+}
+
+// atomHandler serves the news as an Atom feed.
+func atomHandler(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	base := siteBaseURL()
+	items := feedNews(lang, clock())
+	feed := atomFeed{
+		Lang:  lang,
+		ID:    base + "/news.atom?lang=" + lang,
+		Title: feedTitles[lang],
+		Links: []atomLink{
+			{Href: base + "/news.atom?lang=" + lang, Rel: "self", Type: "application/atom+xml"},
+			{Href: base + "/news?lang=" + lang, Rel: "alternate", Type: "text/html"},
+		},
+		Author:  atomAuthor{Name: "Yang Tai Chi Hamburg"},
+		Updated: clock().UTC().Format(time.RFC3339),
+	}
+	if latest := latestNewsStart(items); !latest.IsZero() {
+		feed.Updated = latest.UTC().Format(time.RFC3339)
+	}
+	for _, e := range items {
+		entry := atomEntry{
+			Lang:      e.Fallback,
+			ID:        newsItemID(e.UID),
+			Title:     e.Summary,
+			Published: e.Start.UTC().Format(time.RFC3339),
+			Updated:   e.Start.UTC().Format(time.RFC3339),
+			Link:      atomLink{Href: base + e.NewsPath() + "?lang=" + lang, Rel: "alternate", Type: "text/html"},
+		}
+		if content := newsContentHTML(e); content != "" {
+			entry.Content = &atomText{Type: "html", Value: content}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	slog.Debug("serve atom", "lang", lang, "entries", len(items))
+	writeFeed(w, "application/atom+xml; charset=utf-8", feed)
+}
+
+// jsonFeedHandler serves the news as a JSON Feed.
+func jsonFeedHandler(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	base := siteBaseURL()
+	items := feedNews(lang, clock())
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feedTitles[lang],
+		HomePageURL: base + "/news?lang=" + lang,
+		FeedURL:     base + "/news.json?lang=" + lang,
+		Language:    lang,
+		Authors:     []jsonFeedAuthor{{Name: "Yang Tai Chi Hamburg"}},
+		Items:       []jsonFeedItem{},
+	}
+	for _, e := range items {
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            newsItemID(e.UID),
+			URL:           base + e.NewsPath() + "?lang=" + lang,
+			Title:         e.Summary,
+			ContentHTML:   newsContentHTML(e),
+			DatePublished: e.Start.UTC().Format(time.RFC3339),
+			Language:      e.Fallback,
+			Image:         absoluteURL(base, e.Image),
+		})
+	}
+	slog.Debug("serve json feed", "lang", lang, "items", len(items))
+	w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(feed); err != nil {
+		slog.Error("encode json feed", "err", err)
+	}
+}
+
+func writeFeed(w http.ResponseWriter, contentType string, feed any) {
+	w.Header().Set("Content-Type", contentType)
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	enc := xml.NewEncoder(&b)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		slog.Error("encode feed", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := w.Write([]byte(b.String())); err != nil {
+		slog.Error("write feed", "err", err)
+	}
+}
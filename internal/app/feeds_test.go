@@ -0,0 +1,116 @@
+package app
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewsFeeds(t *testing.T) {
+	config = Config{BaseURL: "https://example.org/"}
+	defer func() { config = Config{} }()
+	newsURLs = map[string]string{"news": ""}
+	published := time.Date(2025, 5, 10, 9, 0, 0, 0, time.UTC)
+	useTestCalendar(t, "news", newsICS("news-1@ytc", "Neue Kurse & Termine", published))
+
+	w := httptest.NewRecorder()
+	rssHandler(w, httptest.NewRequest("GET", "http://attacker.example/news.rss?lang=en", nil))
+	var rss rssFeed
+	if err := xml.Unmarshal(w.Body.Bytes(), &rss); err != nil {
+		t.Fatalf("invalid RSS %q: %v", w.Body.String(), err)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/rss+xml") || rss.Channel.Language != "en" {
+		t.Errorf("unexpected RSS channel %+v", rss.Channel)
+	}
+	if len(rss.Channel.Items) != 1 {
+		t.Fatalf("expected one item, got %+v", rss.Channel.Items)
+	}
+	item := rss.Channel.Items[0]
+	if item.GUID.Value != "news-1@ytc" || item.GUID.IsPermaLink || item.PubDate != "Sat, 10 May 2025 09:00:00 +0000" || item.Title != "Neue Kurse & Termine" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if item.Link != "https://example.org/news/2025/neue-kurse-termine?lang=en" {
+		t.Errorf("expected absolute permalink, got %q", item.Link)
+	}
+
+	w = httptest.NewRecorder()
+	atomHandler(w, httptest.NewRequest("GET", "http://attacker.example/news.atom", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="de">`) {
+		t.Errorf("expected Atom feed in German, got %q", body)
+	}
+	for _, want := range []string{"<id>urn:ytc:news:news-1@ytc</id>", "<published>2025-05-10T09:00:00Z</published>", `href="https://example.org/news.atom?lang=de" rel="self"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in %q", want, body)
+		}
+	}
+}
+
+func TestJSONFeed(t *testing.T) {
+	newsURLs = map[string]string{"news": ""}
+	published := time.Date(2025, 5, 10, 9, 0, 0, 0, time.UTC)
+	ics := strings.Replace(newsICS("news-1@ytc", "Neue Kurse", published), "END:VEVENT",
+		"DESCRIPTION:Anmeldung: https://example.org/kurse.\\n<b>Bitte</b> pünktlich.\\n\\nBis bald\r\nEND:VEVENT", 1)
+	useTestCalendar(t, "news", ics)
+
+	w := httptest.NewRecorder()
+	jsonFeedHandler(w, httptest.NewRequest("GET", "http://attacker.example/news.json?lang=en", nil))
+	var feed jsonFeed
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("invalid JSON feed %q: %v", w.Body.String(), err)
+	}
+	if feed.Version != "https://jsonfeed.org/version/1.1" || feed.Language != "en" || feed.FeedURL != defaultBaseURL+"/news.json?lang=en" {
+		t.Errorf("unexpected feed %+v", feed)
+	}
+	if len(feed.Items) != 1 || feed.Items[0].ID != newsItemID("news-1@ytc") || feed.Items[0].DatePublished != "2025-05-10T09:00:00Z" {
+		t.Fatalf("unexpected items %+v", feed.Items)
+	}
+	want := `<p>Anmeldung: <a href="https://example.org/kurse">https://example.org/kurse</a>.<br>&lt;b&gt;Bitte&lt;/b&gt; pünktlich.</p><p>Bis bald</p>`
+	if got := feed.Items[0].ContentHTML; got != want {
+		t.Errorf("expected sanitized content\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestFeedsHideUnpublishedAndExpiredNews(t *testing.T) {
+	now := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return now }
+	defer func() { clock = time.Now }()
+	newsURLs = map[string]string{"news": ""}
+	useTestCalendar(t, "news", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+		"BEGIN:VEVENT\r\nUID:current\r\nSUMMARY:Aktuell\r\nDTSTART:20250701T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:expired\r\nSUMMARY:Vorbei\r\nDTSTART:20250601T090000Z\r\nX-YTC-EXPIRES:20250705T000000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:future\r\nSUMMARY:Herbst\r\nDTSTART:20250901T090000Z\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+
+	items := feedNews("de", now)
+	if len(items) != 1 || items[0].UID != "current" {
+		t.Errorf("expected only the current item, got %+v", items)
+	}
+}
+
+func TestFeedsDateFromNewestItem(t *testing.T) {
+	newsURLs = map[string]string{"news": ""}
+	useTestCalendar(t, "news", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+		"BEGIN:VEVENT\r\nUID:pinned\r\nSUMMARY:Jubiläum\r\nX-YTC-PINNED:TRUE\r\nDTSTART:20250101T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:recent\r\nSUMMARY:Neue Kurse\r\nDTSTART:20250705T090000Z\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+
+	w := httptest.NewRecorder()
+	rssHandler(w, httptest.NewRequest("GET", "/news.rss?lang=de", nil))
+	var rss rssFeed
+	if err := xml.Unmarshal(w.Body.Bytes(), &rss); err != nil {
+		t.Fatalf("invalid RSS %q: %v", w.Body.String(), err)
+	}
+	if rss.Channel.Items[0].GUID.Value != "pinned" || rss.Channel.LastBuildDate != "Sat, 05 Jul 2025 09:00:00 +0000" {
+		t.Errorf("expected the pinned item first and the date of the newest one, got %+v", rss.Channel)
+	}
+
+	w = httptest.NewRecorder()
+	atomHandler(w, httptest.NewRequest("GET", "/news.atom?lang=de", nil))
+	if !strings.Contains(w.Body.String(), "<updated>2025-07-05T09:00:00Z</updated>\n  <link") {
+		t.Errorf("expected the feed to be updated with the newest item, got %q", w.Body.String())
+	}
+}
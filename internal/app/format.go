@@ -0,0 +1,225 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// clock returns the current time; tests replace it to render pages at a fixed moment.
+var clock = time.Now
+
+// defaultTimeZone is the zone event times are shown in unless the config names another.
+const defaultTimeZone = "Europe/Berlin"
+
+// displayLocation is the time zone event times are shown in.
+var displayLocation = mustLoadLocation(defaultTimeZone)
+
+// mustLoadLocation loads the named zone from the embedded zone database.
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// maxRelativeDays is how far ahead events get an "in N days" label.
+const maxRelativeDays = 30
+
+// locale holds the names and conventions used to format dates, times and durations in one language.
+type locale struct {
+	weekdays [7]string
+	months   [12]string
+	dayFirst bool
+	hour12   bool
+	days     [2]string
+	hours    [2]string
+	minutes  [2]string
+	running  string
+	today    string
+	tomorrow string
+	thisWeek string
+	inDays   string
+}
+
+var locales = map[string]locale{
+	"de": {
+		weekdays: [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		months:   [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		dayFirst: true,
+		days:     [2]string{"Tag", "Tage"},
+		hours:    [2]string{"Stunde", "Stunden"},
+		minutes:  [2]string{"Minute", "Minuten"},
+		running:  "läuft gerade",
+		today:    "heute",
+		tomorrow: "morgen",
+		thisWeek: "diese Woche",
+		inDays:   "in %d Tagen",
+	},
+	"en": {
+		weekdays: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		months:   [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		hour12:   true,
+		days:     [2]string{"day", "days"},
+		hours:    [2]string{"hour", "hours"},
+		minutes:  [2]string{"minute", "minutes"},
+		running:  "in progress",
+		today:    "today",
+		tomorrow: "tomorrow",
+		thisWeek: "this week",
+		inDays:   "in %d days",
+	},
+}
+
+// localeFor returns the locale of lang, falling back to the default language.
+func localeFor(lang string) locale {
+	if l, ok := locales[lang]; ok {
+		return l
+	}
+	return locales[defaultLang]
+}
+
+// date formats t as weekday, day and month, e.g. "Mo., 12. Mai" or "Mon, May 12".
+func (l locale) date(t time.Time) string {
+	if l.dayFirst {
+		return fmt.Sprintf("%s, %d. %s", l.weekdays[t.Weekday()], t.Day(), l.months[t.Month()-1])
+	}
+	return fmt.Sprintf("%s, %s %d", l.weekdays[t.Weekday()], l.months[t.Month()-1], t.Day())
+}
+
+// dateYear formats t like date followed by the year.
+func (l locale) dateYear(t time.Time) string {
+	if l.dayFirst {
+		return fmt.Sprintf("%s %d", l.date(t), t.Year())
+	}
+	return fmt.Sprintf("%s, %d", l.date(t), t.Year())
+}
+
+// time formats the time of day of t in the 12- or 24-hour convention of the locale.
+func (l locale) time(t time.Time) string {
+	if l.hour12 {
+		return t.Format("3:04 PM")
+	}
+	return t.Format("15:04")
+}
+
+// dateTime formats t as date and time of day, e.g. "Mo., 12. Mai, 18:00".
+func (l locale) dateTime(t time.Time) string {
+	return l.date(t) + ", " + l.time(t)
+}
+
+// timeRange formats the period from start to end, omitting the second date if both are on the same day.
+func (l locale) timeRange(start, end time.Time) string {
+	if end.IsZero() || end.Equal(start) {
+		return l.dateTime(start)
+	}
+	if sameDay(start, end) {
+		return l.dateTime(start) + "–" + l.time(end)
+	}
+	return l.dateTime(start) + " – " + l.dateTime(end)
+}
+
+// dateRange formats the all-day period from start to the exclusive end.
+func (l locale) dateRange(start, end time.Time) string {
+	last := end.AddDate(0, 0, -1)
+	if !last.After(start) {
+		return l.date(start)
+	}
+	return l.date(start) + " – " + l.date(last)
+}
+
+// duration formats d in days, hours and minutes, e.g. "1 Stunde 30 Minuten" or "1 hour 30 minutes".
+func (l locale) duration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	days := int(d.Hours()) / 24
+	h := int(d.Hours()) % 24
+	m := int(d.Minutes()) % 60
+	var parts []string
+	if days > 0 {
+		parts = append(parts, pluralize(days, l.days))
+	}
+	if h > 0 {
+		parts = append(parts, pluralize(h, l.hours))
+	}
+	if m > 0 || len(parts) == 0 {
+		parts = append(parts, pluralize(m, l.minutes))
+	}
+	return strings.Join(parts, " ")
+}
+
+// relative returns a label like "heute", "morgen", "diese Woche" or "in 5 Tagen" for an event,
+// "läuft gerade" while it takes place, or "" if it is over or too far ahead. Days are counted in loc.
+func (l locale) relative(e CalendarEvent, now time.Time, loc *time.Location) string {
+	now = now.In(loc)
+	start, end := e.Start.In(loc), e.End.In(loc)
+	if e.AllDay {
+		start, end = dateIn(e.Start, loc), dateIn(e.End, loc)
+	}
+	if !now.Before(start) {
+		if !e.End.IsZero() && now.Before(end) {
+			return l.running
+		}
+		return ""
+	}
+	days := daysBetween(now, start)
+	weekday := (int(now.Weekday()) + 6) % 7 // Monday is the first day of the week
+	switch {
+	case days == 0:
+		return l.today
+	case days == 1:
+		return l.tomorrow
+	case weekday+days <= 6:
+		return l.thisWeek
+	case days <= maxRelativeDays:
+		return fmt.Sprintf(l.inDays, days)
+	}
+	return ""
+}
+
+// dateIn returns midnight in loc of the calendar date of the all-day time t.
+func dateIn(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// daysBetween returns the number of calendar days from a to b, both in the same location.
+func daysBetween(a, b time.Time) int {
+	ad := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	bd := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(bd.Sub(ad).Hours() / 24)
+}
+
+func pluralize(n int, words [2]string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, words[0])
+	}
+	return fmt.Sprintf("%d %s", n, words[1])
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// formatFuncs returns the template functions that format dates, times and durations in lang.
+// Times are converted to loc; all-day dates are shown as they are.
+func formatFuncs(lang string, loc *time.Location) map[string]any {
+	l := localeFor(lang)
+	in := func(t time.Time) time.Time { return t.In(loc) }
+	return map[string]any{
+		"formatDate":      func(t time.Time) string { return l.date(in(t)) },
+		"formatDateYear":  func(t time.Time) string { return l.dateYear(in(t)) },
+		"formatTime":      func(t time.Time) string { return l.time(in(t)) },
+		"formatDateTime":  func(t time.Time) string { return l.dateTime(in(t)) },
+		"formatRange":     func(start, end time.Time) string { return l.timeRange(in(start), in(end)) },
+		"formatDateRange": l.dateRange,
+		"formatDuration":  l.duration,
+		"relative":        func(e CalendarEvent) string { return l.relative(e, clock(), loc) },
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocaleFormatting(t *testing.T) {
+	start := time.Date(2025, 5, 12, 18, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	tests := []struct {
+		lang, got, want string
+	}{
+		{"de", localeFor("de").date(start), "Mo., 12. Mai"},
+		{"en", localeFor("en").date(start), "Mon, May 12"},
+		{"de", localeFor("de").dateYear(start), "Mo., 12. Mai 2025"},
+		{"en", localeFor("en").dateYear(start), "Mon, May 12, 2025"},
+		{"de", localeFor("de").time(start), "18:00"},
+		{"en", localeFor("en").time(start), "6:00 PM"},
+		{"de", localeFor("de").timeRange(start, end), "Mo., 12. Mai, 18:00–19:30"},
+		{"en", localeFor("en").timeRange(start, end), "Mon, May 12, 6:00 PM–7:30 PM"},
+		{"en", localeFor("en").timeRange(start, start.Add(24*time.Hour)), "Mon, May 12, 6:00 PM – Tue, May 13, 6:00 PM"},
+		{"de", localeFor("de").dateRange(start, start.AddDate(0, 0, 1)), "Mo., 12. Mai"},
+		{"de", localeFor("de").dateRange(start, start.AddDate(0, 0, 14)), "Mo., 12. Mai – So., 25. Mai"},
+		{"de", localeFor("de").duration(end.Sub(start)), "1 Stunde 30 Minuten"},
+		{"en", localeFor("en").duration(26 * time.Hour), "1 day 2 hours"},
+		{"en", localeFor("en").duration(0), "0 minutes"},
+		{"fr", localeFor("fr").date(start), "Mo., 12. Mai"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q; want %q", tt.lang, tt.got, tt.want)
+		}
+	}
+}
+
+func TestCalendarTemplateUsesLocaleFormatting(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	start := time.Date(2025, 5, 12, 18, 0, 0, 0, displayLocation)
+	events := []CalendarEvent{{Summary: "Anfänger", Calendar: "wochenkurse", Start: start, End: start.Add(90 * time.Minute)}}
+	for lang, want := range map[string]string{"de": "Mo., 12. Mai, 18:00", "en": "Mon, May 12, 6:00 PM"} {
+		var b strings.Builder
+		if err := templatesByLang[lang].ExecuteTemplate(&b, "calendar.html", buildTemplateData(lang, "", events, map[string]bool{})); err != nil {
+			t.Fatalf("render %s: %v", lang, err)
+		}
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected %q in %s calendar", want, lang)
+		}
+	}
+}
+
+func TestRelativeLabels(t *testing.T) {
+	berlin := time.FixedZone("CEST", 2*60*60)
+	now := time.Date(2025, 5, 14, 10, 0, 0, 0, berlin) // Wednesday
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 5, day, hour, minute, 0, 0, berlin) }
+	tests := []struct {
+		name  string
+		event CalendarEvent
+		lang  string
+		loc   *time.Location
+		want  string
+	}{
+		{"today", CalendarEvent{Start: at(14, 18, 0), End: at(14, 19, 30)}, "de", berlin, "heute"},
+		{"running", CalendarEvent{Start: at(14, 9, 0), End: at(14, 11, 0)}, "de", berlin, "läuft gerade"},
+		{"running en", CalendarEvent{Start: at(14, 9, 0), End: at(14, 11, 0)}, "en", berlin, "in progress"},
+		{"tomorrow", CalendarEvent{Start: at(15, 18, 0)}, "en", berlin, "tomorrow"},
+		{"this week", CalendarEvent{Start: at(17, 10, 0)}, "de", berlin, "diese Woche"},
+		{"next week", CalendarEvent{Start: at(19, 18, 0)}, "de", berlin, "in 5 Tagen"},
+		{"over", CalendarEvent{Start: at(13, 18, 0), End: at(13, 19, 0)}, "de", berlin, ""},
+		{"far ahead", CalendarEvent{Start: now.AddDate(0, 2, 0)}, "de", berlin, ""},
+		{"after midnight in display zone", CalendarEvent{Start: time.Date(2025, 5, 14, 23, 30, 0, 0, time.UTC)}, "de", berlin, "morgen"},
+		{"same instant in UTC", CalendarEvent{Start: time.Date(2025, 5, 14, 23, 30, 0, 0, time.UTC)}, "de", time.UTC, "heute"},
+		{"all day", CalendarEvent{Start: time.Date(2025, 5, 15, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 5, 16, 0, 0, 0, 0, time.UTC), AllDay: true}, "de", berlin, "morgen"},
+		{"all day running", CalendarEvent{Start: time.Date(2025, 5, 14, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 5, 15, 0, 0, 0, 0, time.UTC), AllDay: true}, "de", berlin, "läuft gerade"},
+	}
+	for _, tt := range tests {
+		if got := localeFor(tt.lang).relative(tt.event, now, tt.loc); got != tt.want {
+			t.Errorf("%s: got %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCalendarTemplateRendersRelativeLabels(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	now := time.Date(2025, 5, 14, 10, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return now }
+	defer func() { clock = time.Now }()
+	events := []CalendarEvent{
+		{Summary: "Morgenkurs", Calendar: "wochenkurse", Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+		{Summary: "Abendkurs", Calendar: "wochenkurse", Start: now.Add(24 * time.Hour)},
+	}
+	var b strings.Builder
+	if err := templatesByLang["de"].ExecuteTemplate(&b, "calendar.html", buildTemplateData("de", "", events, map[string]bool{})); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, want := range []string{"läuft gerade", "morgen"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected %q in rendered calendar", want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	trialCalendar    = "schnupperstunden"
+	defaultHomeLimit = 3
+)
+
+// HomeConfig selects the calendars whose next events are listed on the home page.
+// Empty Calendars mean all calendars except the trial lessons, which are shown separately.
+// AnnouncementsOnly hides the latest news so that only active announcements are shown.
+type HomeConfig struct {
+	Calendars         []string `json:"calendars"`
+	Limit             int      `json:"limit"`
+	AnnouncementsOnly bool     `json:"announcementsOnly"`
+}
+
+type HomeTemplateData struct {
+	Page       string
+	Lang       string
+	Events     []CalendarEvent
+	NextTrial  *CalendarEvent
+	LatestNews *NewsItem
+	// Announcements are the pinned or expiring news items that are currently active.
+	Announcements []NewsItem
+	CalColors     map[string]string
+	// Banner is the news item shown in the dismissible banner and the footer ticker, if any.
+	Banner *NewsItem
+}
+
+// homeCalendars returns the configured home page calendars, falling back to all but the trial lessons.
+func (c HomeConfig) homeCalendars() []string {
+	if len(c.Calendars) > 0 {
+		return c.Calendars
+	}
+	cals := make([]string, 0, len(calendarURLs))
+	for cal := range calendarURLs {
+		if cal != trialCalendar {
+			cals = append(cals, cal)
+		}
+	}
+	sort.Strings(cals)
+	return cals
+}
+
+// homeLimit returns the number of events listed on the home page.
+func (c HomeConfig) homeLimit() int {
+	if c.Limit <= 0 {
+		return defaultHomeLimit
+	}
+	return c.Limit
+}
+
+// homeHandler renders the home page with the next events, the next trial lesson and the latest news.
+func homeHandler(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	tmpl, ok := templateFor(lang, getLocation(w, r))
+	if !ok {
+		slog.Error("template not found for language", "lang", lang)
+		http.Error(w, "Template not found", http.StatusInternalServerError)
+		return
+	}
+	data := buildHomeData(lang, clock())
+	slog.Debug("renderTemplate", "lang", lang, "page", "home.html", "events", len(data.Events))
+	if err := tmpl.ExecuteTemplate(w, "home.html", data); err != nil {
+		slog.Error("render template", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func buildHomeData(lang string, now time.Time) HomeTemplateData {
+	data := HomeTemplateData{
+		Page:      "home",
+		Lang:      lang,
+		CalColors: calendarColors,
+		Banner:    bannerNews(lang, now),
+	}
+	homeCals := config.Home.homeCalendars()
+	selected := make(map[string]bool, len(homeCals))
+	for _, cal := range homeCals {
+		selected[cal] = true
+	}
+	cals := homeCals
+	if _, ok := calendarURLs[trialCalendar]; ok && !selected[trialCalendar] {
+		cals = append(cals[:len(cals):len(cals)], trialCalendar)
+	}
+	limit := config.Home.homeLimit()
+	for _, e := range fetchCalendarEvents(cals, now) {
+		if e.IsClosure {
+			continue
+		}
+		if e.Calendar == trialCalendar && data.NextTrial == nil && !e.Cancelled && e.Start.After(now) {
+			trial := e
+			data.NextTrial = &trial
+		}
+		if selected[e.Calendar] && len(data.Events) < limit {
+			data.Events = append(data.Events, e)
+		}
+	}
+	news := activeNews(newsForLang(newsCache.get(), lang), now)
+	data.Announcements = activeAnnouncements(news, now)
+	if len(news) > 0 && !config.Home.AnnouncementsOnly {
+		data.LatestNews = &news[0]
+	}
+	return data
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+// useTestCalendar serves the given iCal text for a calendar until the test ends. The news
+// store is refilled when it is a news calendar, as its background refresh would.
+func useTestCalendar(t *testing.T, name, ics string) {
+	t.Helper()
+	cal, err := ical.ParseCalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerEventSource(name, &memorySource{cal: cal})
+	newsCache = &newsStore{}
+	if _, ok := newsURLs[name]; ok {
+		newsCache.refresh()
+	}
+	t.Cleanup(func() {
+		delete(eventSources, name)
+		newsCache = &newsStore{}
+	})
+}
+
+func TestBuildHomeData(t *testing.T) {
+	now := time.Now()
+	calendarURLs = map[string]string{"wochenkurse": "", "sonderkurse": "", trialCalendar: ""}
+	newsURLs = map[string]string{"news": ""}
+	useTestCalendar(t, "wochenkurse", icsWithEvent("w1", "Anfänger", now.Add(24*time.Hour)))
+	useTestCalendar(t, "sonderkurse", icsWithEvent("s1", "Workshop", now.Add(48*time.Hour)))
+	useTestCalendar(t, trialCalendar, icsWithEvent("t1", "Schnupperstunde", now.Add(72*time.Hour)))
+	useTestCalendar(t, "news", newsICS("n1", "Neue Kurse", now.Add(-24*time.Hour)))
+	config = Config{Home: HomeConfig{Limit: 1}}
+	defer func() { config = Config{} }()
+
+	data := buildHomeData("de", now)
+	if len(data.Events) != 1 || data.Events[0].UID != "w1" {
+		t.Errorf("expected only the next class, got %+v", data.Events)
+	}
+	if data.NextTrial == nil || data.NextTrial.UID != "t1" {
+		t.Errorf("expected next trial lesson, got %+v", data.NextTrial)
+	}
+	if data.LatestNews == nil || data.LatestNews.Summary != "Neue Kurse" {
+		t.Errorf("expected latest news, got %+v", data.LatestNews)
+	}
+
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	w := httptest.NewRecorder()
+	homeHandler(w, httptest.NewRequest("GET", "/?lang=en", nil))
+	body := w.Body.String()
+	for _, want := range []string{"Next classes", "Anfänger", "Next trial lesson", "Schnupperstunde", "Neue Kurse"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q on home page", want)
+		}
+	}
+}
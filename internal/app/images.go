@@ -0,0 +1,243 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+const (
+	// propertyImage names the header image of a news item: a file of the embedded images or a URL.
+	propertyImage = "X-YTC-IMAGE"
+	maxImageSize  = 10 << 20
+	imageMaxAge   = 24 * time.Hour
+)
+
+var imageClient = &http.Client{Timeout: 30 * time.Second}
+
+// imageStore serves news images that are not part of the embedded images: remote images are
+// downloaded once and kept in a cache directory, so browsers never contact their host, and
+// files next to Markdown posts are served from disk. Only registered images are served.
+type imageStore struct {
+	mu      sync.Mutex
+	dir     string
+	sources map[string]string
+	fetches map[string]*sync.Mutex
+}
+
+var newsImages = &imageStore{sources: map[string]string{}, fetches: map[string]*sync.Mutex{}}
+
+// setDir sets the cache directory for remote images; empty means a directory in the temp dir.
+func (s *imageStore) setDir(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dir = dir
+}
+
+func (s *imageStore) cacheDir() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dir == "" {
+		return filepath.Join(os.TempDir(), "ytc-news-images")
+	}
+	return s.dir
+}
+
+// register returns the path under which the remote URL or local file source is served.
+func (s *imageStore) register(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	key := hex.EncodeToString(sum[:16])
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[key] = source
+	return "/news/images/" + key
+}
+
+func (s *imageStore) source(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	source, ok := s.sources[key]
+	return source, ok
+}
+
+// retain drops the images and download locks that none of items uses any more; the news store
+// calls it after every refresh, which registers the current images again.
+func (s *imageStore) retain(items []NewsItem) {
+	used := make(map[string]bool, len(items))
+	for _, e := range items {
+		if key, ok := strings.CutPrefix(e.Image, "/news/images/"); ok {
+			used[key] = true
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.sources {
+		if !used[key] {
+			delete(s.sources, key)
+		}
+	}
+	for key := range s.fetches {
+		if !used[key] {
+			delete(s.fetches, key)
+		}
+	}
+}
+
+// fetchLock serialises downloads of the same image.
+func (s *imageStore) fetchLock(key string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.fetches[key]
+	if !ok {
+		l = &sync.Mutex{}
+		s.fetches[key] = l
+	}
+	return l
+}
+
+// cached returns the cached file and content type of a remote image, downloading it if it is
+// missing or older than imageMaxAge. A stale copy is used if the download fails.
+func (s *imageStore) cached(key, url string) (string, string, error) {
+	l := s.fetchLock(key)
+	l.Lock()
+	defer l.Unlock()
+	dir := s.cacheDir()
+	file := filepath.Join(dir, key)
+	info, statErr := os.Stat(file)
+	if statErr == nil && time.Since(info.ModTime()) < imageMaxAge {
+		contentType, err := os.ReadFile(file + ".type")
+		if err == nil {
+			return file, string(contentType), nil
+		}
+	}
+	contentType, err := downloadImage(url, dir, key)
+	if err != nil {
+		if statErr == nil {
+			slog.Warn("refresh news image, serving cached copy", "url", url, "err", err)
+			stale, _ := os.ReadFile(file + ".type")
+			return file, string(stale), nil
+		}
+		return "", "", err
+	}
+	slog.Info("Cached news image", "url", url, "key", key)
+	return file, contentType, nil
+}
+
+// downloadImage stores the image at url as dir/key and its content type as dir/key.type.
+func downloadImage(url, dir, key string) (string, error) {
+	resp, err := imageClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); !strings.HasPrefix(mediaType, "image/") {
+		return "", fmt.Errorf("not an image: %q", contentType)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxImageSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	if n > maxImageSize {
+		return "", errors.New("image too large")
+	}
+	if err := os.WriteFile(filepath.Join(dir, key+".type"), []byte(contentType), 0o644); err != nil {
+		return "", err
+	}
+	return contentType, os.Rename(tmp.Name(), filepath.Join(dir, key))
+}
+
+// newsImageHandler serves a registered news image from /news/images/{key}.
+func newsImageHandler(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	source, ok := newsImages.source(key)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if !isRemoteImage(source) {
+		http.ServeFile(w, r, source)
+		return
+	}
+	file, contentType, err := newsImages.cached(key, source)
+	if err != nil {
+		slog.Error("fetch news image", "url", source, "err", err)
+		http.Error(w, "Image not available", http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	http.ServeFile(w, r, file)
+}
+
+func isRemoteImage(ref string) bool {
+	return strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://")
+}
+
+// resolveImage returns the URL of a news image given as a URL, as a file of the embedded
+// images or as a file relative to dir, or "" if it cannot be found.
+func resolveImage(ref, dir string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	if isRemoteImage(ref) {
+		return newsImages.register(ref)
+	}
+	name := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(ref)), "/")
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "api/"), "images/")
+	if _, err := fs.Stat(imagesFS, "static/images/"+name); err == nil {
+		return "/api/images/" + name
+	}
+	if dir != "" && filepath.IsLocal(ref) {
+		file := filepath.Join(dir, ref)
+		if _, err := os.Stat(file); err == nil {
+			return newsImages.register(file)
+		}
+	}
+	slog.Warn("news image not found", "image", ref)
+	return ""
+}
+
+// parseNewsImage returns the header image of a news calendar item from X-YTC-IMAGE or the first
+// ATTACH with an image media type.
+func parseNewsImage(e *ical.VEvent) string {
+	if prop := e.GetProperty(ical.ComponentProperty(propertyImage)); prop != nil {
+		return resolveImage(prop.Value, "")
+	}
+	for _, prop := range e.GetProperties(ical.ComponentPropertyAttach) {
+		fmtType := prop.ICalParameters[string(ical.ParameterFmttype)]
+		if len(fmtType) > 0 && strings.HasPrefix(strings.ToLower(fmtType[0]), "image/") && isRemoteImage(prop.Value) {
+			return resolveImage(prop.Value, "")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewsImages(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path == "/page.html" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png-data"))
+	}))
+	defer srv.Close()
+	newsImages.setDir(t.TempDir())
+	defer newsImages.setDir("")
+
+	newsURLs = map[string]string{"news": ""}
+	ics := strings.Replace(newsICS("n1", "Sommerfest", time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)), "END:VEVENT",
+		"ATTACH;FMTTYPE=application/pdf:"+srv.URL+"/flyer.pdf\r\nATTACH;FMTTYPE=image/png:"+srv.URL+"/fest.png\r\nEND:VEVENT", 1)
+	useTestCalendar(t, "news", ics)
+	events := fetchNewsEvents()
+	if len(events) != 1 || !strings.HasPrefix(events[0].Image, "/news/images/") {
+		t.Fatalf("expected proxied attachment image, got %+v", events)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/news/images/{key}", newsImageHandler)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", events[0].Image, nil))
+		if w.Code != http.StatusOK || w.Body.String() != "png-data" || w.Header().Get("Content-Type") != "image/png" {
+			t.Fatalf("unexpected image response %d %q %q", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected the image to be downloaded once, got %d requests", hits)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", resolveImage(srv.URL+"/page.html", ""), nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("expected non-image to be rejected, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/news/images/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unregistered image, got %d", w.Code)
+	}
+
+	// A refresh without the item forgets its image.
+	useTestCalendar(t, "news", newsICS("n2", "Herbst", time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC)))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", events[0].Image, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for the image of a removed item, got %d", w.Code)
+	}
+	newsImages.mu.Lock()
+	defer newsImages.mu.Unlock()
+	if len(newsImages.sources) != 0 || len(newsImages.fetches) != 0 {
+		t.Errorf("expected unused images and locks to be dropped, got %v, %v", newsImages.sources, newsImages.fetches)
+	}
+}
+
+func TestResolveImage(t *testing.T) {
+	if got := resolveImage("images/school1.jpg", ""); got != "/api/images/school1.jpg" {
+		t.Errorf("expected embedded image, got %q", got)
+	}
+	dir := t.TempDir()
+	writePost(t, dir, "header.jpg", "jpeg-data")
+	writePost(t, dir, "fest.md", "---\ntitle: Fest\ndate: 2025-06-01\nimage: header.jpg\n---\nText\n")
+	post, err := readPost(filepath.Join(dir, "fest.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/news/images/{key}", newsImageHandler)
+	mux.ServeHTTP(w, httptest.NewRequest("GET", post.Image, nil))
+	if w.Body.String() != "jpeg-data" {
+		t.Errorf("expected image next to the post, got %q", w.Body.String())
+	}
+	if got := resolveImage("../secret.jpg", dir); got != "" {
+		t.Errorf("expected paths outside the news directory to be ignored, got %q", got)
+	}
+}
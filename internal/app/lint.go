@@ -0,0 +1,160 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+
+	"github.com/WillyWinkel/ytc/internal/utils"
+)
+
+// Lint severities.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintIssue is a problem found in a calendar feed.
+type LintIssue struct {
+	Source   string `json:"source"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	UID      string `json:"uid,omitempty"`
+	Event    int    `json:"event,omitempty"`
+	Message  string `json:"message"`
+}
+
+// LintCalendars fetches and checks the given calendars, configured calendar names or file
+// paths and URLs. Without targets all configured calendars and the news calendar are checked.
+func LintCalendars(targets []string) []LintIssue {
+	if len(targets) == 0 {
+		for name := range calendarURLs {
+			targets = append(targets, name)
+		}
+		for name := range newsURLs {
+			targets = append(targets, name)
+		}
+		sort.Strings(targets)
+	}
+	var issues []LintIssue
+	for _, target := range targets {
+		cal, err := fetchLintTarget(target)
+		if err != nil {
+			issues = append(issues, LintIssue{Source: target, Severity: LintError, Rule: "fetch", Message: err.Error()})
+			continue
+		}
+		issues = append(issues, lintCalendar(target, cal)...)
+	}
+	return issues
+}
+
+func fetchLintTarget(target string) (*ical.Calendar, error) {
+	if url, ok := calendarURLs[target]; ok {
+		return loadCalendar(target, url)
+	}
+	if url, ok := newsURLs[target]; ok {
+		return loadCalendar(target, url)
+	}
+	return fetchSource(SourceConfig{URL: target})
+}
+
+// lintCalendar checks the events of one calendar.
+func lintCalendar(source string, cal *ical.Calendar) []LintIssue {
+	var issues []LintIssue
+	report := func(severity, rule, uid string, event int, format string, args ...any) {
+		issues = append(issues, LintIssue{Source: source, Severity: severity, Rule: rule, UID: uid, Event: event, Message: fmt.Sprintf(format, args...)})
+	}
+	seen := make(map[string]int)
+	for i, e := range cal.Events() {
+		n := i + 1
+		uid, recurrenceID, _ := parseEventIdentity(e)
+		if uid == "" {
+			report(LintError, "missing-uid", "", n, "event has no UID")
+		} else if recurrenceID == "" {
+			if first, ok := seen[uid]; ok {
+				report(LintError, "duplicate-uid", uid, n, "UID already used by event %d", first)
+			} else {
+				seen[uid] = n
+			}
+		}
+		if prop := e.GetProperty(ical.ComponentPropertySummary); prop == nil || strings.TrimSpace(prop.Value) == "" {
+			report(LintWarning, "empty-summary", uid, n, "event has no summary")
+		}
+
+		var start, end time.Time
+		for _, p := range []struct {
+			name ical.ComponentProperty
+			t    *time.Time
+		}{{ical.ComponentPropertyDtStart, &start}, {ical.ComponentPropertyDtEnd, &end}} {
+			prop := e.GetProperty(p.name)
+			if prop == nil {
+				if p.name == ical.ComponentPropertyDtStart {
+					report(LintError, "missing-dtstart", uid, n, "event has no DTSTART")
+				}
+				continue
+			}
+			if t, _ := utils.ParseICalTimeToHuman(prop.Value); t.IsZero() {
+				report(LintError, "invalid-date", uid, n, "%s %q cannot be parsed", p.name, prop.Value)
+				continue
+			}
+			if tzid := prop.ICalParameters[string(ical.ParameterTzid)]; len(tzid) > 0 {
+				if _, err := time.LoadLocation(tzid[0]); err != nil {
+					report(LintError, "unknown-tzid", uid, n, "%s has unknown TZID %q", p.name, tzid[0])
+					continue
+				}
+			}
+			*p.t = parseEventTime(prop)
+		}
+		if !start.IsZero() && !end.IsZero() && end.Before(start) {
+			report(LintError, "end-before-start", uid, n, "DTEND %s is before DTSTART %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+		}
+		if prop := e.GetProperty(ical.ComponentPropertyRrule); prop != nil {
+			if _, err := parseRecurrenceRule(prop.Value); err != nil {
+				report(LintWarning, "unsupported-rrule", uid, n, "RRULE is not expanded: %v", err)
+			}
+		}
+	}
+	return issues
+}
+
+// HasLintErrors reports whether any issue is an error rather than a warning.
+func HasLintErrors(issues []LintIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteLintIssues writes the issues as a table or, with format "json", as a JSON array.
+func WriteLintIssues(w io.Writer, issues []LintIssue, format string) error {
+	if format == "json" {
+		if issues == nil {
+			issues = []LintIssue{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(issues)
+	}
+	if len(issues) == 0 {
+		_, err := fmt.Fprintln(w, "No problems found.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tSEVERITY\tRULE\tEVENT\tUID\tMESSAGE")
+	for _, issue := range issues {
+		event := "-"
+		if issue.Event > 0 {
+			event = fmt.Sprint(issue.Event)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", issue.Source, issue.Severity, issue.Rule, event, issue.UID, issue.Message)
+	}
+	return tw.Flush()
+}
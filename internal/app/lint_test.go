@@ -0,0 +1,65 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+const brokenICS = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n" +
+	"BEGIN:VEVENT\r\nSUMMARY:Ohne UID\r\nDTSTART:20250512T180000Z\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Rückwärts\r\nDTSTART:20250512T180000Z\r\nDTEND:20250512T170000Z\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:a\r\nSUMMARY: \r\nDTSTART;TZID=Mitteleuropa:20250512T180000\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:b\r\nSUMMARY:Kaputt\r\nDTSTART:12.05.2025 18:00\r\nRRULE:FREQ=WEEKLY;BYSETPOS=1\r\nEND:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestLintCalendar(t *testing.T) {
+	cal, err := ical.ParseCalendar(strings.NewReader(brokenICS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := make(map[string]LintIssue)
+	for _, issue := range lintCalendar("test", cal) {
+		rules[issue.Rule] = issue
+	}
+	for _, rule := range []string{"missing-uid", "end-before-start", "duplicate-uid", "empty-summary", "unknown-tzid", "invalid-date", "unsupported-rrule"} {
+		if _, ok := rules[rule]; !ok {
+			t.Errorf("expected %s issue, got %+v", rule, rules)
+		}
+	}
+	if rules["duplicate-uid"].Event != 3 || rules["empty-summary"].Severity != LintWarning {
+		t.Errorf("unexpected issues %+v", rules)
+	}
+
+	valid, err := ical.ParseCalendar(strings.NewReader(weeklyICS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issues := lintCalendar("weekly", valid); len(issues) != 0 {
+		t.Errorf("expected no issues for valid feed, got %+v", issues)
+	}
+}
+
+func TestLintCalendarsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.ics")
+	if err := os.WriteFile(path, []byte(brokenICS), 0644); err != nil {
+		t.Fatal(err)
+	}
+	issues := LintCalendars([]string{path, filepath.Join(t.TempDir(), "missing.ics")})
+	if !HasLintErrors(issues) || issues[len(issues)-1].Rule != "fetch" {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+	var out bytes.Buffer
+	if err := WriteLintIssues(&out, issues, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []LintIssue
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded) != len(issues) {
+		t.Errorf("unexpected JSON %q", out.String())
+	}
+}
@@ -1,79 +1,156 @@
 package app
 
 import (
-	ical "github.com/arran4/golang-ical"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"sort"
-	"strings"
+	"strconv"
 	"time"
 
-	"github.com/WillyWinkel/ytc/internal/utils"
+	ical "github.com/arran4/golang-ical"
 )
 
+// NewsTemplateData is passed to the news list and the yearly archive. Year is zero on /news.
+type NewsTemplateData struct {
+	Page          string
+	Lang          string
+	Events        []NewsItem
+	CalWebcalURLs map[string]string
+	Year          int
+	Years         []NewsYear
+	BasePath      string
+	PageNum       int
+	Pages         []int
+	Banner        *NewsItem
+}
+
+// NewsYear is an archive year with its number of news items.
+type NewsYear struct {
+	Year  int
+	Count int
+}
+
+// newsHandler serves the news page by page.
 func newsHandler(w http.ResponseWriter, r *http.Request) {
+	renderNewsList(w, r, 0)
+}
+
+// newsArchiveHandler serves the news of the year in /news/archive/{year}.
+func newsArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	year, err := strconv.Atoi(r.PathValue("year"))
+	if err != nil || year < 1 {
+		http.NotFound(w, r)
+		return
+	}
+	renderNewsList(w, r, year)
+}
+
+func renderNewsList(w http.ResponseWriter, r *http.Request, year int) {
 	lang := getLang(r)
-	tmpl, ok := templatesByLang[lang]
+	tmpl, ok := templateFor(lang, getLocation(w, r))
 	if !ok {
 		slog.Error("template not found for language", "lang", lang)
 		http.Error(w, "Template not found", http.StatusInternalServerError)
 		return
 	}
-	events := fetchNewsEvents()
-	data := TemplateData{
+	all := newsForLang(newsCache.get(), lang)
+	data := NewsTemplateData{
 		Page:          "news",
 		Lang:          lang,
-		Events:        events,
 		CalWebcalURLs: newsURLs,
+		Year:          year,
+		Years:         newsYears(all),
+		BasePath:      "/news",
+		Banner:        bannerNews(lang, clock()),
+	}
+	events := activeNews(all, clock())
+	if year != 0 {
+		data.BasePath = fmt.Sprintf("/news/archive/%d", year)
+		events = nil
+		for _, e := range all {
+			if e.Start.In(displayLocation).Year() == year {
+				events = append(events, e)
+			}
+		}
+		if len(events) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	size := config.News.pageSize()
+	pageCount := max(1, (len(events)+size-1)/size)
+	data.PageNum = 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 || n > pageCount {
+			http.NotFound(w, r)
+			return
+		}
+		data.PageNum = n
 	}
-	slog.Debug("renderTemplate", "lang", lang, "page", "news.html", "events", len(events))
+	for i := 1; i <= pageCount; i++ {
+		data.Pages = append(data.Pages, i)
+	}
+	data.Events = events[(data.PageNum-1)*size : min(data.PageNum*size, len(events))]
+	slog.Debug("renderTemplate", "lang", lang, "page", "news.html", "year", year, "pageNum", data.PageNum, "events", len(data.Events))
 	if err := tmpl.ExecuteTemplate(w, "news.html", data); err != nil {
 		slog.Error("render template", "err", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
-func fetchNewsEvents() []CalendarEvent {
-	calendarURL, ok := newsURLs["news"]
-	if !ok {
-		slog.Error("news calendar not found")
-		return nil
-	}
-	calendarURL = strings.ReplaceAll(calendarURL, "webcal://", "https://")
-	cal, err := ical.ParseCalendarFromUrl(calendarURL)
-	if err != nil {
-		slog.Error("parse news calendar", "err", err)
-		return nil
+// newsYears counts the news items per year, newest year first.
+func newsYears(events []NewsItem) []NewsYear {
+	counts := make(map[int]int)
+	for _, e := range events {
+		if !e.Start.IsZero() {
+			counts[e.Start.In(displayLocation).Year()]++
+		}
 	}
-	var events []eventWithTime
-	for _, e := range cal.Events() {
-		event, startTime, _ := parseEventNews(e)
-		events = append(events, eventWithTime{
-			CalendarEvent: event,
-			startTime:     startTime,
-		})
+	years := make([]NewsYear, 0, len(counts))
+	for year, count := range counts {
+		years = append(years, NewsYear{Year: year, Count: count})
 	}
-	sort.Slice(events, func(i, j int) bool {
-		return events[i].startTime.After(events[j].startTime)
+	sort.Slice(years, func(i, j int) bool {
+		return years[i].Year > years[j].Year
 	})
-	result := make([]CalendarEvent, len(events))
-	for i, e := range events {
-		result[i] = e.CalendarEvent
+	return years
+}
+
+// fetchNewsEvents returns the items of the news calendar and the Markdown posts, pinned items
+// first and otherwise newest first. Expired items are kept for the archive and their
+// permalinks; activeNews filters them out.
+func fetchNewsEvents() []NewsItem {
+	var events []NewsItem
+	if calendarURL, ok := newsURLs["news"]; !ok {
+		slog.Error("news calendar not found")
+	} else if cal, err := loadCalendar("news", calendarURL); err != nil {
+		slog.Error("parse news calendar", "err", err)
+	} else {
+		for _, e := range cal.Events() {
+			events = append(events, parseEventNews(e))
+		}
 	}
-	return result
+	events = append(events, newsPosts.get()...)
+	assignSlugs(events)
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Pinned != events[j].Pinned {
+			return events[i].Pinned
+		}
+		return events[i].Start.After(events[j].Start)
+	})
+	return events
 }
 
-func parseEventNews(e *ical.VEvent) (CalendarEvent, time.Time, time.Time) {
+func parseEventNews(e *ical.VEvent) NewsItem {
 	var (
-		startStr, summary, description string
-		startTime                      time.Time
+		summary, description string
+		startTime            time.Time
 	)
+	uid, recurrenceID, status := parseEventIdentity(e)
 	if prop := e.GetProperty(ical.ComponentPropertyDtStart); prop != nil {
-		t, _ := utils.ParseICalTimeToHuman(prop.Value)
-		startTime = t
-		if !t.IsZero() {
-			startStr = t.Format("2.1.")
-		}
+		startTime = parseEventTime(prop)
 	}
 	if prop := e.GetProperty(ical.ComponentPropertySummary); prop != nil {
 		summary = prop.Value
@@ -81,9 +158,29 @@ func parseEventNews(e *ical.VEvent) (CalendarEvent, time.Time, time.Time) {
 	if prop := e.GetProperty(ical.ComponentPropertyDescription); prop != nil {
 		description = prop.Value
 	}
-	return CalendarEvent{
-		Summary:     summary,
-		Description: description,
-		Start:       startStr,
-	}, startTime, time.Time{}
+	var slug string
+	if prop := e.GetProperty(ical.ComponentProperty(propertySlug)); prop != nil {
+		slug = slugify(prop.Value)
+	}
+	pinned, expires := parseNewsLifetime(e, startTime)
+	textLang, text, translations := parseNewsTexts(summary, description)
+	summary, description = text.Summary, text.Description
+	return NewsItem{
+		CalendarEvent: CalendarEvent{
+			UID:          uid,
+			RecurrenceID: recurrenceID,
+			Status:       status,
+			Cancelled:    status == "CANCELLED",
+			Summary:      summary,
+			Description:  description,
+			Start:        startTime,
+			Calendar:     "news",
+		},
+		Slug:         slug,
+		Pinned:       pinned,
+		Expires:      expires,
+		TextLang:     textLang,
+		Translations: translations,
+		Image:        parseNewsImage(e),
+	}
 }
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// langMarkerPattern matches the language markers that start a block of a bilingual news
+// text, e.g. "Neue Kurse [en] New classes".
+var langMarkerPattern = regexp.MustCompile(`\[([a-z]{2})\]`)
+
+// splitLangBlocks splits text at the markers of the supported languages. Text before the first
+// marker is in the default language.
+func splitLangBlocks(text string) map[string]string {
+	blocks := make(map[string]string)
+	lang, last := defaultLang, 0
+	for _, m := range langMarkerPattern.FindAllStringSubmatchIndex(text, -1) {
+		marker := text[m[2]:m[3]]
+		if marker != defaultLang && !isSupportedLang(marker) {
+			continue
+		}
+		if block := strings.TrimSpace(text[last:m[0]]); block != "" {
+			blocks[lang] = block
+		}
+		lang, last = marker, m[1]
+	}
+	if block := strings.TrimSpace(text[last:]); block != "" {
+		blocks[lang] = block
+	}
+	return blocks
+}
+
+// parseNewsTexts splits a bilingual summary and description. It returns the text in the
+// default language (or the first language there is) together with the other translations.
+func parseNewsTexts(summary, description string) (string, NewsText, map[string]NewsText) {
+	summaries, descriptions := splitLangBlocks(summary), splitLangBlocks(description)
+	texts := make(map[string]NewsText)
+	for lang, s := range summaries {
+		texts[lang] = NewsText{Summary: s, Description: descriptions[lang]}
+	}
+	for lang, d := range descriptions {
+		texts[lang] = NewsText{Summary: summaries[lang], Description: d}
+	}
+	textLang := defaultLang
+	if _, ok := texts[defaultLang]; !ok && len(texts) > 0 {
+		langs := slices.Sorted(maps.Keys(texts))
+		textLang = langs[0]
+	}
+	text := texts[textLang]
+	delete(texts, textLang)
+	for lang, t := range texts {
+		if t.Summary == "" {
+			t.Summary = text.Summary
+		}
+		if t.Description == "" {
+			t.Description = text.Description
+		}
+		texts[lang] = t
+	}
+	if len(texts) == 0 {
+		texts = nil
+	}
+	return textLang, text, texts
+}
+
+// newsForLang returns the items that are not limited to another language, with their text
+// translated into lang where a translation exists and Fallback set where it does not.
+func newsForLang(events []NewsItem, lang string) []NewsItem {
+	var result []NewsItem
+	for _, e := range events {
+		if e.Lang != "" && e.Lang != lang {
+			continue
+		}
+		if t, ok := e.Translations[lang]; ok {
+			e.Summary, e.Description, e.TextLang = t.Summary, t.Description, lang
+		} else if e.TextLang != "" && e.TextLang != lang {
+			e.Fallback = e.TextLang
+		}
+		result = append(result, e)
+	}
+	return result
+}
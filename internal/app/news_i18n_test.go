@@ -0,0 +1,43 @@
+package app
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBilingualNews(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	newsURLs = map[string]string{"news": ""}
+	useTestCalendar(t, "news", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+		"BEGIN:VEVENT\r\nUID:both\r\nSUMMARY:Neue Kurse [en] New classes\r\nDESCRIPTION:[de]\\nAb Mai.\\n[en]\\nFrom May [sic].\r\nDTSTART:20250510T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:german\r\nSUMMARY:Sommerfest\r\nDESCRIPTION:Im Park.\r\nDTSTART:20250601T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:english\r\nSUMMARY:[en] Guest teacher\r\nDTSTART:20250701T090000Z\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+
+	events := fetchNewsEvents()
+	if events[2].UID != "both" || events[2].Summary != "Neue Kurse" || events[2].Slug != "neue-kurse" {
+		t.Errorf("expected default language text and slug, got %+v", events[2])
+	}
+	en := newsForLang(events, "en")
+	if en[2].Summary != "New classes" || en[2].Description != "From May [sic]." || en[2].Fallback != "" {
+		t.Errorf("expected English translation, got %+v", en[2])
+	}
+	if en[1].Summary != "Sommerfest" || en[1].Fallback != "de" {
+		t.Errorf("expected German fallback, got %+v", en[1])
+	}
+	if de := newsForLang(events, "de"); de[0].Summary != "Guest teacher" || de[0].Fallback != "en" || de[2].Description != "Ab Mai." {
+		t.Errorf("unexpected German news %+v", de)
+	}
+
+	w := httptest.NewRecorder()
+	newsHandler(w, httptest.NewRequest("GET", "/news?lang=en", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `<span lang="de">Sommerfest</span><span class="badge text-bg-light border fw-normal ms-2 news-fallback" data-fallback-lang="de">Only available in German</span>`) {
+		t.Errorf("expected visible fallback, got %q", body)
+	}
+	if !strings.Contains(body, "<span>New classes</span>") {
+		t.Error("expected translated summary without fallback marker")
+	}
+}
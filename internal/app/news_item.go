@@ -0,0 +1,82 @@
+package app
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// NewsItemTemplateData is passed to the news detail page. Prev is the next older and Next the
+// next newer item.
+type NewsItemTemplateData struct {
+	Page        string
+	Lang        string
+	Item        NewsItem
+	Prev, Next  *NewsItem
+	URL         string
+	Locale      string
+	Description string
+	ImageURL    string
+	Banner      *NewsItem
+}
+
+var ogLocales = map[string]string{"de": "de_DE", "en": "en_US"}
+
+// newsItemHandler serves the detail page of the news item at /news/{year}/{slug}.
+func newsItemHandler(w http.ResponseWriter, r *http.Request) {
+	lang := getLang(r)
+	tmpl, ok := templateFor(lang, getLocation(w, r))
+	if !ok {
+		slog.Error("template not found for language", "lang", lang)
+		http.Error(w, "Template not found", http.StatusInternalServerError)
+		return
+	}
+	path := "/news/" + r.PathValue("year") + "/" + r.PathValue("slug")
+	events := newsForLang(newsCache.get(), lang)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.After(events[j].Start)
+	})
+	data := NewsItemTemplateData{Page: "news", Lang: lang, Banner: bannerNews(lang, clock())}
+	found := false
+	for i, e := range events {
+		if e.NewsPath() != path {
+			continue
+		}
+		data.Item, found = e, true
+		if i > 0 {
+			data.Next = &events[i-1]
+		}
+		if i+1 < len(events) {
+			data.Prev = &events[i+1]
+		}
+		break
+	}
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	data.URL = siteBaseURL() + path + "?lang=" + lang
+	data.Locale = ogLocales[lang]
+	data.ImageURL = absoluteURL(siteBaseURL(), data.Item.Image)
+	data.Description = summarizeText(data.Item.Description, 200)
+	slog.Debug("renderTemplate", "lang", lang, "page", "news_item.html", "path", path)
+	if err := tmpl.ExecuteTemplate(w, "news_item.html", data); err != nil {
+		slog.Error("render template", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// summarizeText collapses whitespace and shortens text to at most limit runes at a word boundary.
+func summarizeText(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	cut := string(runes[:limit])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
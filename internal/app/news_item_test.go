@@ -0,0 +1,45 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewsItemHandler(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	config = Config{BaseURL: "https://example.org"}
+	defer func() { config = Config{} }()
+	newsURLs = map[string]string{"news": ""}
+	first := time.Date(2025, 5, 10, 9, 0, 0, 0, time.UTC)
+	ics := strings.Replace(newsICS("n1", "Neue Kurse", first), "END:VCALENDAR",
+		"BEGIN:VEVENT\r\nUID:n2\r\nSUMMARY:Sommerfest\r\nX-YTC-SLUG:Fest 2025\r\nDESCRIPTION:Wir feiern im Park.\r\nDTSTART:20250601T090000Z\r\nEND:VEVENT\r\n"+
+			"BEGIN:VEVENT\r\nUID:n3\r\nSUMMARY:Herbst\r\nDTSTART:20250901T090000Z\r\nEND:VEVENT\r\nEND:VCALENDAR", 1)
+	useTestCalendar(t, "news", ics)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/news/{year}/{slug}", newsItemHandler)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "http://attacker.example/news/2025/fest-2025?lang=en", nil))
+	body := w.Body.String()
+	for _, want := range []string{
+		`<meta property="og:title" content="Sommerfest">`,
+		`<meta property="og:url" content="https://example.org/news/2025/fest-2025?lang=en">`,
+		`<meta property="og:description" content="Wir feiern im Park.">`,
+		`href="/news/2025/neue-kurse?lang=en" rel="prev"`,
+		`href="/news/2025/herbst?lang=en" rel="next"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in %q", want, body)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/news/2024/fest-2025", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for wrong year, got %d", w.Code)
+	}
+}
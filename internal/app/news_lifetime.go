@@ -0,0 +1,62 @@
+package app
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+// News item properties; CATEGORIES:pinned works as well as X-YTC-PINNED:TRUE.
+const (
+	propertyPinned  = "X-YTC-PINNED"
+	propertyExpires = "X-YTC-EXPIRES"
+	categoryPinned  = "pinned"
+)
+
+// parseNewsLifetime reads whether a news item is pinned and when it expires. X-YTC-EXPIRES
+// sets the expiry explicitly; otherwise a DTEND after DTSTART does.
+func parseNewsLifetime(e *ical.VEvent, start time.Time) (pinned bool, expires time.Time) {
+	if prop := e.GetProperty(ical.ComponentProperty(propertyPinned)); prop != nil {
+		pinned, _ = strconv.ParseBool(strings.TrimSpace(prop.Value))
+	}
+	for _, prop := range e.GetProperties(ical.ComponentPropertyCategories) {
+		for _, category := range strings.Split(prop.Value, ",") {
+			if strings.EqualFold(strings.TrimSpace(category), categoryPinned) {
+				pinned = true
+			}
+		}
+	}
+	if prop := e.GetProperty(ical.ComponentProperty(propertyExpires)); prop != nil {
+		return pinned, parseEventTime(prop)
+	}
+	if prop := e.GetProperty(ical.ComponentPropertyDtEnd); prop != nil {
+		if end := parseEventTime(prop); !start.IsZero() && end.After(start) {
+			expires = end
+		}
+	}
+	return pinned, expires
+}
+
+// activeNews returns the news items that have not expired.
+func activeNews(events []NewsItem, now time.Time) []NewsItem {
+	var result []NewsItem
+	for _, e := range events {
+		if e.Expires.IsZero() || now.Before(e.Expires) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// activeAnnouncements returns the news items that are pinned or expire, and have been published.
+func activeAnnouncements(events []NewsItem, now time.Time) []NewsItem {
+	var result []NewsItem
+	for _, e := range events {
+		if (e.Pinned || !e.Expires.IsZero()) && !e.Start.After(now) && (e.Expires.IsZero() || now.Before(e.Expires)) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewsLifetime(t *testing.T) {
+	now := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return now }
+	defer func() { clock = time.Now }()
+	newsURLs = map[string]string{"news": ""}
+	useTestCalendar(t, "news", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+		"BEGIN:VEVENT\r\nUID:no-end\r\nSUMMARY:Neue Kurse\r\nDTSTART;VALUE=DATE:20250701\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:today\r\nSUMMARY:Heute geschlossen\r\nDTSTART:20250710T090000Z\r\nDTEND:20250710T180000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:expired\r\nSUMMARY:Osterpause\r\nDTSTART;VALUE=DATE:20250401\r\nDTEND;VALUE=DATE:20250422\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:summer\r\nSUMMARY:Sommerpause\r\nDTSTART;VALUE=DATE:20250601\r\nDTEND;VALUE=DATE:20250602\r\nX-YTC-EXPIRES;VALUE=DATE:20250815\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:pinned\r\nSUMMARY:Jubiläum\r\nCATEGORIES:Schule,Pinned\r\nDTSTART:20250101T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:future\r\nSUMMARY:Herbst\r\nX-YTC-PINNED:TRUE\r\nDTSTART:20250901T090000Z\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+
+	uids := func(events []NewsItem) string {
+		var uids []string
+		for _, e := range events {
+			uids = append(uids, e.UID)
+		}
+		return strings.Join(uids, ",")
+	}
+	events := fetchNewsEvents()
+	if got := uids(events); got != "future,pinned,today,no-end,summer,expired" {
+		t.Errorf("expected pinned items first and expired items kept, got %s", got)
+	}
+	if got := uids(activeNews(events, now)); got != "future,pinned,today,no-end,summer" {
+		t.Errorf("expected expired items to be inactive, got %s", got)
+	}
+	if got := uids(activeNews(events, now.Add(8*time.Hour))); got != "future,pinned,no-end,summer" {
+		t.Errorf("expected a same-day DTEND to expire the item, got %s", got)
+	}
+	active := activeAnnouncements(events, now)
+	if got := uids(active); got != "pinned,today,summer" {
+		t.Errorf("expected pinned and expiring announcements, got %+v", active)
+	}
+
+	config = Config{Home: HomeConfig{AnnouncementsOnly: true}}
+	defer func() { config = Config{} }()
+	calendarURLs = map[string]string{}
+	data := buildHomeData("de", now)
+	if data.LatestNews != nil || len(data.Announcements) != 3 {
+		t.Errorf("expected only announcements on the home page, got %+v", data)
+	}
+}
+
+func TestExpiredNewsStayReachable(t *testing.T) {
+	now := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return now }
+	defer func() { clock = time.Now }()
+	loadTemplates()
+	newsURLs = map[string]string{"news": ""}
+	useTestCalendar(t, "news", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+		"BEGIN:VEVENT\r\nUID:current\r\nSUMMARY:Neue Kurse\r\nDTSTART:20250701T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:expired\r\nSUMMARY:Osterpause\r\nDTSTART:20250401T090000Z\r\nX-YTC-EXPIRES:20250422T000000Z\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/news/2025/osterpause?lang=de", nil)
+	r.SetPathValue("year", "2025")
+	r.SetPathValue("slug", "osterpause")
+	newsItemHandler(w, r)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Osterpause") {
+		t.Errorf("expected the permalink of an expired item to resolve, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	newsHandler(w, httptest.NewRequest("GET", "/news?lang=de", nil))
+	if strings.Contains(w.Body.String(), "Osterpause") {
+		t.Error("expected the expired item to be hidden from the news list")
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/news/archive/2025?lang=de", nil)
+	r.SetPathValue("year", "2025")
+	newsArchiveHandler(w, r)
+	if !strings.Contains(w.Body.String(), "Osterpause") {
+		t.Error("expected the expired item in the yearly archive")
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// propertySlug sets the permalink slug of a news calendar item.
+const propertySlug = "X-YTC-SLUG"
+
+var slugReplacer = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+
+// slugify turns a title into a lowercase slug of ASCII letters, digits and dashes.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range slugReplacer.Replace(strings.ToLower(s)) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// assignSlugs gives every news item without an explicit slug one derived from its summary.
+// Slugs that are already taken in the same year get a numeric suffix, explicit slugs first,
+// assigned in order of start and UID so that they stay the same across refreshes.
+func assignSlugs(events []NewsItem) {
+	var explicit, derived []int
+	for i, e := range events {
+		if e.Slug != "" {
+			explicit = append(explicit, i)
+		} else {
+			derived = append(derived, i)
+		}
+	}
+	byStart := func(indexes []int) {
+		sort.Slice(indexes, func(i, j int) bool {
+			a, b := events[indexes[i]], events[indexes[j]]
+			if !a.Start.Equal(b.Start) {
+				return a.Start.Before(b.Start)
+			}
+			return a.UID < b.UID
+		})
+	}
+	byStart(explicit)
+	byStart(derived)
+	taken := make(map[string]bool)
+	unique := func(i int, base string) {
+		slug := base
+		for n := 2; taken[newsPath(events[i].Start, slug)]; n++ {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		events[i].Slug = slug
+		taken[newsPath(events[i].Start, slug)] = true
+	}
+	for _, i := range explicit {
+		slug := events[i].Slug
+		unique(i, slug)
+		if events[i].Slug != slug {
+			slog.Error("duplicate news slug", "uid", events[i].UID, "slug", slug, "path", events[i].NewsPath())
+		}
+	}
+	for _, i := range derived {
+		base := slugify(events[i].Summary)
+		if base == "" {
+			base = "news"
+		}
+		unique(i, base)
+	}
+}
+
+func newsPath(start time.Time, slug string) string {
+	return fmt.Sprintf("/news/%d/%s", start.In(displayLocation).Year(), slug)
+}
+
+// NewsPath returns the permalink of a news item.
+func (e NewsItem) NewsPath() string {
+	return newsPath(e.Start, e.Slug)
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAssignSlugs(t *testing.T) {
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	events := []NewsItem{
+		{CalendarEvent: CalendarEvent{UID: "b", Summary: "Neue Kurse!", Start: day.AddDate(0, 1, 0)}},
+		{CalendarEvent: CalendarEvent{UID: "a", Summary: "Neue Kurse", Start: day}},
+		{CalendarEvent: CalendarEvent{UID: "c", Summary: "Ärger über Straßen", Start: day}},
+		{CalendarEvent: CalendarEvent{UID: "d", Summary: "Whatever", Start: day}, Slug: "neue-kurse-2"},
+		{CalendarEvent: CalendarEvent{UID: "e", Summary: "Neue Kurse", Start: day.AddDate(1, 0, 0)}},
+	}
+	assignSlugs(events)
+	want := []string{"neue-kurse-3", "neue-kurse", "aerger-ueber-strassen", "neue-kurse-2", "neue-kurse"}
+	for i, e := range events {
+		if e.Slug != want[i] {
+			t.Errorf("event %s: expected slug %q, got %q", e.UID, want[i], e.Slug)
+		}
+	}
+	if path := events[4].NewsPath(); path != "/news/2026/neue-kurse" {
+		t.Errorf("unexpected path %q", path)
+	}
+}
+
+func TestAssignSlugsDuplicateExplicit(t *testing.T) {
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	events := []NewsItem{
+		{CalendarEvent: CalendarEvent{UID: "later", Summary: "Zweite", Start: day.AddDate(0, 0, 1)}, Slug: "sommer"},
+		{CalendarEvent: CalendarEvent{UID: "first", Summary: "Erste", Start: day}, Slug: "sommer"},
+		{CalendarEvent: CalendarEvent{UID: "derived", Summary: "Sommer", Start: day}},
+	}
+	assignSlugs(events)
+	if events[1].Slug != "sommer" || events[0].Slug != "sommer-2" || events[2].Slug != "sommer-3" {
+		t.Errorf("expected unique slugs, got %q, %q, %q", events[0].Slug, events[1].Slug, events[2].Slug)
+	}
+}
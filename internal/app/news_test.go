@@ -0,0 +1,57 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newsICS returns a news calendar with one item; unlike icsWithEvent it has no DTEND, so the
+// item does not expire.
+func newsICS(uid, summary string, start time.Time) string {
+	return strings.Replace(icsWithEvent(uid, summary, start), "\r\nDTEND:"+start.Add(time.Hour).UTC().Format("20060102T150405Z"), "", 1)
+}
+
+func TestNewsPagination(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	newsURLs = map[string]string{"news": ""}
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"
+	for i, day := range []string{"20240310", "20240920", "20250105", "20250301", "20250601"} {
+		ics += "BEGIN:VEVENT\r\nUID:n" + string(rune('1'+i)) + "\r\nSUMMARY:Item " + day + "\r\nDTSTART:" + day + "T090000Z\r\nEND:VEVENT\r\n"
+	}
+	useTestCalendar(t, "news", ics+"END:VCALENDAR\r\n")
+	config = Config{News: NewsConfig{PageSize: 2}}
+	defer func() { config = Config{} }()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/news", newsHandler)
+	mux.HandleFunc("/news/{year}/{slug}", newsItemHandler)
+	mux.HandleFunc("/news/archive/{year}", newsArchiveHandler)
+	get := func(target string) (int, string) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w.Code, w.Body.String()
+	}
+
+	code, body := get("/news?lang=en&page=2")
+	if code != http.StatusOK || !strings.Contains(body, "Item 20250105") || !strings.Contains(body, "Item 20240920") || strings.Contains(body, "Item 20250601") {
+		t.Errorf("expected second page, got %d %q", code, body)
+	}
+	if !strings.Contains(body, `href="/news?lang=en&page=3"`) || !strings.Contains(body, `<span class="badge text-bg-secondary rounded-pill">3</span>`) {
+		t.Errorf("expected page links and year counts, got %q", body)
+	}
+	if code, _ := get("/news?lang=en&page=4"); code != http.StatusNotFound {
+		t.Errorf("expected 404 beyond the last page, got %d", code)
+	}
+
+	code, body = get("/news/archive/2024?lang=en")
+	if code != http.StatusOK || !strings.Contains(body, "News 2024") || !strings.Contains(body, "Item 20240310") || strings.Contains(body, "Item 20250105") {
+		t.Errorf("expected 2024 archive, got %d %q", code, body)
+	}
+	if code, _ := get("/news/archive/2019"); code != http.StatusNotFound {
+		t.Errorf("expected 404 for a year without news, got %d", code)
+	}
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+//go:embed static/js/news-visits.js
+var newsVisitsJS []byte
+
+// newsLatest is the response of /api/news/latest.
+type newsLatest struct {
+	Latest *time.Time `json:"latest"`
+	Count  int        `json:"count"`
+}
+
+// newsLatestHandler returns when the newest published news item in lang appeared and, with
+// the since query parameter, how many items were published after that time. The navbar uses
+// it to show the number of news since the visitor's last visit without a cookie.
+func newsLatestHandler(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "invalid since", http.StatusBadRequest)
+			return
+		}
+	}
+	now := clock()
+	var result newsLatest
+	for _, e := range activeNews(newsForLang(newsCache.get(), getLang(r)), now) {
+		if e.Start.IsZero() || e.Start.After(now) {
+			continue
+		}
+		if result.Latest == nil || e.Start.After(*result.Latest) {
+			start := e.Start.UTC()
+			result.Latest = &start
+		}
+		if !since.IsZero() && e.Start.After(since) {
+			result.Count++
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		slog.Error("encode latest news", "err", err)
+	}
+}
+
+// newsVisitsHandler serves the script that marks news since the visitor's last visit.
+func newsVisitsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if _, err := w.Write(newsVisitsJS); err != nil {
+		slog.Error("write news visits script", "err", err)
+	}
+}
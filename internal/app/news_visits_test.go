@@ -0,0 +1,38 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewsLatestHandler(t *testing.T) {
+	now := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return now }
+	defer func() { clock = time.Now }()
+	newsURLs = map[string]string{"news": ""}
+	useTestCalendar(t, "news", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+		"BEGIN:VEVENT\r\nUID:a\r\nSUMMARY:A\r\nDTSTART:20250601T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:b\r\nSUMMARY:B\r\nDTSTART:20250705T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:c\r\nSUMMARY:[en] C\r\nDTSTART:20250708T090000Z\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:future\r\nSUMMARY:Later\r\nDTSTART:20250801T090000Z\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+
+	w := httptest.NewRecorder()
+	newsLatestHandler(w, httptest.NewRequest("GET", "/api/news/latest?lang=de&since=2025-06-15T00:00:00.000Z", nil))
+	if got := strings.TrimSpace(w.Body.String()); got != `{"latest":"2025-07-08T09:00:00Z","count":2}` {
+		t.Errorf("unexpected response %s", got)
+	}
+	w = httptest.NewRecorder()
+	newsLatestHandler(w, httptest.NewRequest("GET", "/api/news/latest", nil))
+	if got := strings.TrimSpace(w.Body.String()); got != `{"latest":"2025-07-08T09:00:00Z","count":0}` {
+		t.Errorf("unexpected response without since %s", got)
+	}
+	w = httptest.NewRecorder()
+	newsLatestHandler(w, httptest.NewRequest("GET", "/api/news/latest?since=yesterday", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid since, got %d", w.Code)
+	}
+}
@@ -0,0 +1,220 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// NewsConfig configures the news page. Dir is a directory of Markdown posts that are shown
+// together with the news calendar; PageSize is the number of items per page. Remote news
+// images are cached in ImageCache.
+type NewsConfig struct {
+	Dir        string `json:"dir"`
+	PageSize   int    `json:"pageSize"`
+	ImageCache string `json:"imageCache"`
+}
+
+const defaultNewsPageSize = 10
+
+// pageSize returns the number of news items per page.
+func (c NewsConfig) pageSize() int {
+	if c.PageSize <= 0 {
+		return defaultNewsPageSize
+	}
+	return c.PageSize
+}
+
+// markdown renders post bodies. Raw HTML in a post is dropped.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+var postDateLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+// postStore loads the Markdown posts of a directory and reloads them when a file is added,
+// removed or modified.
+type postStore struct {
+	mu    sync.Mutex
+	dir   string
+	stamp string
+	posts []NewsItem
+}
+
+var newsPosts = &postStore{}
+
+// setDir switches the store to the posts in dir.
+func (s *postStore) setDir(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dir = dir
+	s.stamp = ""
+	s.posts = nil
+}
+
+// get returns the current posts.
+func (s *postStore) get() []NewsItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dir == "" {
+		return nil
+	}
+	files, stamp, err := postFiles(s.dir)
+	if err != nil {
+		slog.Error("read news directory", "dir", s.dir, "err", err)
+		return s.posts
+	}
+	if stamp == s.stamp {
+		return s.posts
+	}
+	var posts []NewsItem
+	for _, file := range files {
+		post, err := readPost(file)
+		if err != nil {
+			slog.Warn("skip news post", "path", file, "err", err)
+			continue
+		}
+		posts = append(posts, post)
+	}
+	s.posts = posts
+	s.stamp = stamp
+	slog.Info("Loaded news posts", "dir", s.dir, "posts", len(posts))
+	return s.posts
+}
+
+// postFiles lists the Markdown files in dir with a stamp that changes whenever one of them does.
+func postFiles(dir string) ([]string, string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	var (
+		files []string
+		stamp strings.Builder
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, "", err
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+		fmt.Fprintf(&stamp, "%s|%d|%d\n", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	sort.Strings(files)
+	return files, stamp.String(), nil
+}
+
+// readPost parses a Markdown file with front matter into a news item. Its UID is the file
+// name without the extension.
+func readPost(path string) (NewsItem, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return NewsItem{}, err
+	}
+	meta, body, err := splitFrontMatter(string(b))
+	if err != nil {
+		return NewsItem{}, err
+	}
+	post := NewsItem{
+		CalendarEvent: CalendarEvent{
+			UID:         strings.TrimSuffix(filepath.Base(path), ".md"),
+			Summary:     meta["title"],
+			Description: strings.TrimSpace(body),
+			Calendar:    "news",
+		},
+		Lang:     meta["language"],
+		TextLang: meta["language"],
+		Slug:     meta["slug"],
+		Image:    resolveImage(meta["image"], filepath.Dir(path)),
+	}
+	if post.TextLang == "" {
+		post.TextLang = defaultLang
+	}
+	if post.Summary == "" {
+		return post, errors.New("missing title")
+	}
+	if post.Start, err = parsePostDate(meta["date"]); err != nil {
+		return post, fmt.Errorf("date: %w", err)
+	}
+	if v := meta["expires"]; v != "" {
+		if post.Expires, err = parsePostDate(v); err != nil {
+			return post, fmt.Errorf("expires: %w", err)
+		}
+	}
+	if v := meta["pinned"]; v != "" {
+		if post.Pinned, err = strconv.ParseBool(v); err != nil {
+			return post, fmt.Errorf("pinned: %w", err)
+		}
+	}
+	var html bytes.Buffer
+	if err := markdown.Convert([]byte(body), &html); err != nil {
+		return post, err
+	}
+	post.Content = template.HTML(html.String())
+	return post, nil
+}
+
+// splitFrontMatter separates the "key: value" lines between the leading "---" lines from the body.
+func splitFrontMatter(text string) (map[string]string, string, error) {
+	text = strings.ReplaceAll(strings.TrimPrefix(text, "\ufeff"), "\r\n", "\n")
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return nil, "", errors.New("missing front matter")
+	}
+	header, body, ok := strings.Cut(rest, "\n---")
+	if !ok {
+		return nil, "", errors.New("unterminated front matter")
+	}
+	_, body, _ = strings.Cut(body, "\n")
+	meta := make(map[string]string)
+	for _, line := range strings.Split(header, "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, "", fmt.Errorf("invalid front matter line %q", line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' && value[len(value)-1] == '"' || value[0] == '\'' && value[len(value)-1] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		meta[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return meta, body, nil
+}
+
+// parsePostDate parses a front matter date; times without offset are in the display time zone.
+func parsePostDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("missing")
+	}
+	for _, layout := range postDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, displayLocation); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q", value)
+}
+
+// newsContentHTML returns the body of a news item as HTML: the rendered Markdown of a post or
+// the escaped description of a calendar item.
+func newsContentHTML(e NewsItem) string {
+	if e.Content != "" {
+		return string(e.Content)
+	}
+	return descriptionHTML(e.Description)
+}
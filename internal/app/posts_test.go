@@ -0,0 +1,75 @@
+package app
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writePost(t *testing.T, dir, name, text string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadPost(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "sommerpause.md", "---\ntitle: \"Sommerpause\"\ndate: 2025-07-01\nlanguage: de\npinned: true\nexpires: 2025-08-01 12:00\n---\n"+
+		"Wir machen **Pause**.\n\n<script>alert(1)</script>\n")
+	post, err := readPost(filepath.Join(dir, "sommerpause.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.UID != "sommerpause" || post.Summary != "Sommerpause" || post.Lang != "de" || !post.Pinned || post.Calendar != "news" {
+		t.Errorf("unexpected post %+v", post)
+	}
+	if !post.Start.Equal(time.Date(2025, 7, 1, 0, 0, 0, 0, displayLocation)) || post.Expires.Hour() != 12 {
+		t.Errorf("unexpected dates %v, %v", post.Start, post.Expires)
+	}
+	if !strings.Contains(string(post.Content), "<strong>Pause</strong>") || strings.Contains(string(post.Content), "<script>") {
+		t.Errorf("expected rendered Markdown without raw HTML, got %q", post.Content)
+	}
+
+	writePost(t, dir, "broken.md", "---\ndate: 2025-07-01\n---\nNo title\n")
+	if _, err := readPost(filepath.Join(dir, "broken.md")); err == nil {
+		t.Error("expected error for missing title")
+	}
+}
+
+func TestNewsMergesPosts(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	now := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return now }
+	defer func() { clock = time.Now }()
+	newsURLs = map[string]string{"news": ""}
+	useTestCalendar(t, "news", newsICS("n1", "Neue Kurse", now.AddDate(0, 0, -1)))
+	dir := t.TempDir()
+	newsPosts.setDir(dir)
+	defer newsPosts.setDir("")
+	writePost(t, dir, "old.md", "---\ntitle: Jubiläum\ndate: 2025-06-01\npinned: yes\n---\nText\n")
+	writePost(t, dir, "expired.md", "---\ntitle: Vorbei\ndate: 2025-07-05\nexpires: 2025-07-09\n---\nText\n")
+	writePost(t, dir, "english.md", "---\ntitle: Summer break\ndate: 2025-07-08\nlanguage: en\n---\nText\n")
+
+	if events := activeNews(fetchNewsEvents(), now); len(events) != 2 || events[0].Summary != "Neue Kurse" {
+		t.Fatalf("expected calendar item and valid posts, got %+v", events)
+	}
+
+	writePost(t, dir, "old.md", "---\ntitle: Jubiläum\ndate: 2025-06-01\npinned: true\n---\n# Zehn Jahre\n")
+	events := activeNews(newsForLang(fetchNewsEvents(), "en"), now)
+	if len(events) != 3 || events[0].UID != "old" || events[1].UID != "n1" || events[2].UID != "english" {
+		t.Fatalf("expected reloaded pinned post first, got %+v", events)
+	}
+
+	newsCache.refresh()
+	w := httptest.NewRecorder()
+	newsHandler(w, httptest.NewRequest("GET", "/news?lang=de", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "<h1>Zehn Jahre</h1>") || strings.Contains(body, "Summer break") || strings.Contains(body, "Vorbei") {
+		t.Errorf("unexpected news page %q", body)
+	}
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+
+	"github.com/WillyWinkel/ytc/internal/utils"
+)
+
+// maxOccurrences bounds the expansion of a rule, counted from its first occurrence.
+const maxOccurrences = 20000
+
+// recurrenceRule is the subset of an RRULE that expandCalendar understands:
+// FREQ, INTERVAL, COUNT, UNTIL and BYDAY without ordinals.
+type recurrenceRule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRecurrenceRule parses the value of an RRULE property.
+func parseRecurrenceRule(value string) (recurrenceRule, error) {
+	rule := recurrenceRule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+			rule.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("invalid COUNT %q", val)
+			}
+			rule.count = n
+		case "UNTIL":
+			until, _ := utils.ParseICalTimeToHuman(val)
+			if until.IsZero() {
+				return rule, fmt.Errorf("invalid UNTIL %q", val)
+			}
+			rule.until = until
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, ok := icalWeekdays[strings.ToUpper(d)]
+				if !ok {
+					return rule, fmt.Errorf("unsupported BYDAY %q", d)
+				}
+				rule.byDay = append(rule.byDay, wd)
+			}
+		case "WKST":
+		default:
+			return rule, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+	switch rule.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return rule, fmt.Errorf("unsupported FREQ %q", rule.freq)
+	}
+	return rule, nil
+}
+
+// occurrences returns the start times of the rule beginning at start that lie before until.
+func (r recurrenceRule) occurrences(start, until time.Time) []time.Time {
+	var result []time.Time
+	emitted := 0
+	for period := 0; emitted < maxOccurrences; period++ {
+		var candidates []time.Time
+		switch r.freq {
+		case "DAILY":
+			candidates = []time.Time{start.AddDate(0, 0, period*r.interval)}
+		case "WEEKLY":
+			weekStart := start.AddDate(0, 0, period*7*r.interval)
+			if len(r.byDay) == 0 {
+				candidates = []time.Time{weekStart}
+				break
+			}
+			monday := weekStart.AddDate(0, 0, -((int(weekStart.Weekday()) + 6) % 7))
+			for i := 0; i < 7; i++ {
+				day := monday.AddDate(0, 0, i)
+				for _, wd := range r.byDay {
+					if day.Weekday() == wd && !day.Before(start) {
+						candidates = append(candidates, day)
+					}
+				}
+			}
+		case "MONTHLY":
+			candidates = []time.Time{start.AddDate(0, period*r.interval, 0)}
+		case "YEARLY":
+			candidates = []time.Time{start.AddDate(period*r.interval, 0, 0)}
+		}
+		for _, c := range candidates {
+			if !r.until.IsZero() && c.After(r.until) || !c.Before(until) {
+				return result
+			}
+			if (r.freq == "MONTHLY" || r.freq == "YEARLY") && c.Day() != start.Day() {
+				continue // e.g. the 31st in a shorter month
+			}
+			result = append(result, c)
+			emitted++
+			if r.count > 0 && emitted >= r.count {
+				return result
+			}
+		}
+	}
+	return result
+}
+
+// expandCalendar returns the events of cal that start before until and end after from, with
+// recurring events expanded into their occurrences. Events without an end are left out. EXDATEs are skipped and occurrences
+// overridden by a RECURRENCE-ID event are replaced by that event.
+func expandCalendar(cal *ical.Calendar, calName string, from, until time.Time) []CalendarEvent {
+	overrides := make(map[string]CalendarEvent)
+	for _, e := range cal.Events() {
+		if e.GetProperty(ical.ComponentPropertyRecurrenceId) == nil {
+			continue
+		}
+		ev := parseEvent(e, calName)
+		overrides[ev.UID+"|"+occurrenceID(parseEventTime(e.GetProperty(ical.ComponentPropertyRecurrenceId)))] = ev
+	}
+	var events []CalendarEvent
+	inRange := func(ev CalendarEvent) bool {
+		return !ev.End.IsZero() && ev.Start.Before(until) && ev.End.After(from)
+	}
+	for _, e := range cal.Events() {
+		if e.GetProperty(ical.ComponentPropertyRecurrenceId) != nil {
+			continue
+		}
+		master := parseEvent(e, calName)
+		prop := e.GetProperty(ical.ComponentPropertyRrule)
+		if prop == nil || master.Start.IsZero() {
+			if inRange(master) {
+				events = append(events, master)
+			}
+			continue
+		}
+		rule, err := parseRecurrenceRule(prop.Value)
+		if err != nil {
+			slog.Warn("cannot expand recurring event", "calendar", calName, "uid", master.UID, "err", err)
+			if inRange(master) {
+				events = append(events, master)
+			}
+			continue
+		}
+		excluded := make(map[string]bool)
+		for _, ex := range e.GetProperties(ical.ComponentPropertyExdate) {
+			for _, v := range strings.Split(ex.Value, ",") {
+				t := parseEventTime(&ical.IANAProperty{BaseProperty: ical.BaseProperty{IANAToken: ex.IANAToken, ICalParameters: ex.ICalParameters, Value: v}})
+				excluded[occurrenceID(t)] = true
+			}
+		}
+		duration := master.Duration()
+		for _, start := range rule.occurrences(master.Start, until) {
+			id := occurrenceID(start)
+			if excluded[id] {
+				continue
+			}
+			ev, ok := overrides[master.UID+"|"+id]
+			if ok {
+				delete(overrides, master.UID+"|"+id)
+			} else {
+				ev = master
+				ev.Start = start
+				if !master.End.IsZero() {
+					ev.End = start.Add(duration)
+				}
+				ev.RecurrenceID = id
+			}
+			if inRange(ev) {
+				events = append(events, ev)
+			}
+		}
+	}
+	for _, ev := range overrides {
+		if inRange(ev) {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// occurrenceID identifies an occurrence of a recurring event by its UTC start.
+func occurrenceID(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
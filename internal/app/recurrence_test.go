@@ -0,0 +1,136 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+const weeklyICS = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n" +
+	"BEGIN:VEVENT\r\nUID:w1\r\nSUMMARY:Anfänger\r\nLOCATION:Halle 1\r\nORGANIZER;CN=Stephan:mailto:stephan@example.org\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250505T180000\r\nDTEND;TZID=Europe/Berlin:20250505T193000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=6\r\nEXDATE;TZID=Europe/Berlin:20250512T180000\r\nEND:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\nUID:w1\r\nRECURRENCE-ID;TZID=Europe/Berlin:20250519T180000\r\nSUMMARY:Anfänger\r\nLOCATION:Halle 2\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250519T190000\r\nDTEND;TZID=Europe/Berlin:20250519T203000\r\nEND:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestExpandCalendar(t *testing.T) {
+	cal, err := ical.ParseCalendar(strings.NewReader(weeklyICS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	events := expandCalendar(cal, "wochenkurse", from, from.AddDate(0, 0, 30))
+	// May 5, 19 (moved), 26; May 12 is excluded, June 2 and 9 are outside the range.
+	if len(events) != 3 {
+		t.Fatalf("expected 3 occurrences, got %d: %+v", len(events), events)
+	}
+	var moved *CalendarEvent
+	for i := range events {
+		if events[i].Location == "Halle 2" {
+			moved = &events[i]
+		}
+		if events[i].Start.Day() == 12 {
+			t.Error("expected EXDATE occurrence to be skipped")
+		}
+	}
+	if moved == nil || moved.Start.Hour() != 19 {
+		t.Errorf("expected overridden occurrence, got %+v", events)
+	}
+	if events[0].Instructor != "Stephan" || events[0].Start.UTC().Hour() != 16 {
+		t.Errorf("unexpected first occurrence %+v", events[0])
+	}
+}
+
+func TestRecurrenceRuleOccurrences(t *testing.T) {
+	start := time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC)
+	rule, err := parseRecurrenceRule("FREQ=MONTHLY;COUNT=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rule.occurrences(start, start.AddDate(2, 0, 0))
+	if len(got) != 3 || got[1].Month() != time.March || got[2].Month() != time.May {
+		t.Errorf("expected months with a 31st, got %v", got)
+	}
+	if _, err := parseRecurrenceRule("FREQ=WEEKLY;BYSETPOS=1"); err == nil {
+		t.Error("expected error for unsupported rule part")
+	}
+}
+
+func TestRecurrenceRuleCountAndUntil(t *testing.T) {
+	start := time.Date(2025, 5, 5, 18, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		rule string
+		want int
+	}{
+		{"FREQ=DAILY;COUNT=4", 4},
+		{"FREQ=DAILY;INTERVAL=2;COUNT=4", 4},
+		{"FREQ=WEEKLY;UNTIL=20250526T180000Z", 4},
+		{"FREQ=WEEKLY;UNTIL=20250526T175959Z", 3},
+		{"FREQ=WEEKLY;UNTIL=20250526", 3},
+		{"FREQ=WEEKLY;COUNT=10;UNTIL=20250519T180000Z", 3},
+		{"FREQ=YEARLY", 2},
+	} {
+		rule, err := parseRecurrenceRule(tc.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.rule, err)
+		}
+		if got := rule.occurrences(start, start.AddDate(1, 0, 1)); len(got) != tc.want {
+			t.Errorf("%s: expected %d occurrences, got %v", tc.rule, tc.want, got)
+		}
+	}
+	rule, _ := parseRecurrenceRule("FREQ=DAILY;INTERVAL=2;COUNT=3")
+	if got := rule.occurrences(start, start.AddDate(0, 1, 0)); !got[2].Equal(start.AddDate(0, 0, 4)) {
+		t.Errorf("expected every other day, got %v", got)
+	}
+}
+
+func TestRecurrenceRuleByDay(t *testing.T) {
+	// Wednesday, so the Monday of the first week lies before the start.
+	start := time.Date(2025, 5, 7, 18, 0, 0, 0, time.UTC)
+	rule, err := parseRecurrenceRule("FREQ=WEEKLY;BYDAY=MO,WE,FR;COUNT=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var days []string
+	for _, o := range rule.occurrences(start, start.AddDate(0, 1, 0)) {
+		days = append(days, o.Format("Mon 02"))
+	}
+	if got := strings.Join(days, ","); got != "Wed 07,Fri 09,Mon 12,Wed 14,Fri 16" {
+		t.Errorf("unexpected occurrences %s", got)
+	}
+
+	rule, _ = parseRecurrenceRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=TU")
+	got := rule.occurrences(start, start.AddDate(0, 0, 28))
+	// The Tuesday of the first week lies before the start, the next week is skipped.
+	if len(got) != 2 || !got[0].Equal(start.AddDate(0, 0, 13)) || !got[1].Equal(start.AddDate(0, 0, 27)) {
+		t.Errorf("expected every other Tuesday from May 20, got %v", got)
+	}
+
+	for _, value := range []string{"FREQ=WEEKLY;BYDAY=1MO", "FREQ=HOURLY", "FREQ=DAILY;COUNT=0", "FREQ=DAILY;UNTIL=soon"} {
+		if _, err := parseRecurrenceRule(value); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
+
+func TestExpandCalendarExdates(t *testing.T) {
+	cal, err := ical.ParseCalendar(strings.NewReader("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n" +
+		"BEGIN:VEVENT\r\nUID:d1\r\nSUMMARY:Morgens\r\nDTSTART:20250505T070000Z\r\nDTEND:20250505T080000Z\r\n" +
+		"RRULE:FREQ=DAILY;COUNT=5\r\nEXDATE:20250506T070000Z,20250507T070000Z\r\nEXDATE:20250509T070000Z\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:open\r\nSUMMARY:Ohne Ende\r\nDTSTART:20250506T090000Z\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for _, e := range expandCalendar(cal, "sonderkurse", from, from.AddDate(0, 1, 0)) {
+		ids = append(ids, e.UID+"@"+e.RecurrenceID)
+	}
+	if got := strings.Join(ids, ","); got != "d1@20250505T070000Z,d1@20250508T070000Z" {
+		t.Errorf("expected excluded dates and events without an end to be left out, got %s", got)
+	}
+}
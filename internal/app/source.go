@@ -0,0 +1,384 @@
+package app
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+const (
+	defaultCalDAVPastDays   = 30
+	defaultCalDAVFutureDays = 365
+)
+
+// EventSource loads the iCal data of one calendar.
+type EventSource interface {
+	Fetch() (*ical.Calendar, error)
+}
+
+// SourceConfig configures the source of a calendar. The URL scheme selects the backend:
+// webcal://, http:// and https:// fetch a published feed, file:// or a plain path reads a
+// local file, caldav:// (HTTPS) and caldav+http:// query a CalDAV collection. Other schemes
+// are rejected.
+type SourceConfig struct {
+	URL        string `json:"url"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	PastDays   int    `json:"pastDays"`
+	FutureDays int    `json:"futureDays"`
+}
+
+var (
+	eventSourcesMu sync.RWMutex
+	eventSources   = map[string]EventSource{}
+)
+
+var sourceClient = &http.Client{Timeout: 30 * time.Second}
+
+var urlSchemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*$`)
+
+// registerEventSource makes name load its events from src instead of its calendar URL.
+func registerEventSource(name string, src EventSource) {
+	eventSourcesMu.Lock()
+	defer eventSourcesMu.Unlock()
+	eventSources[name] = src
+}
+
+// sourceFor returns the registered source of a calendar, or one derived from its URL.
+func sourceFor(name, rawURL string) (EventSource, error) {
+	eventSourcesMu.RLock()
+	src, ok := eventSources[name]
+	eventSourcesMu.RUnlock()
+	if ok {
+		return src, nil
+	}
+	return newEventSource(SourceConfig{URL: rawURL})
+}
+
+// newEventSource creates the backend matching the scheme of cfg.URL.
+func newEventSource(cfg SourceConfig) (EventSource, error) {
+	switch {
+	case strings.HasPrefix(cfg.URL, "caldav://"):
+		return &calDAVSource{config: cfg, url: "https://" + strings.TrimPrefix(cfg.URL, "caldav://")}, nil
+	case strings.HasPrefix(cfg.URL, "caldav+http://"):
+		return &calDAVSource{config: cfg, url: "http://" + strings.TrimPrefix(cfg.URL, "caldav+http://")}, nil
+	case strings.HasPrefix(cfg.URL, "webcal://"):
+		return &httpSource{url: "https://" + strings.TrimPrefix(cfg.URL, "webcal://"), username: cfg.Username, password: cfg.Password}, nil
+	case strings.HasPrefix(cfg.URL, "http://"), strings.HasPrefix(cfg.URL, "https://"):
+		return &httpSource{url: cfg.URL, username: cfg.Username, password: cfg.Password}, nil
+	case strings.HasPrefix(cfg.URL, "file://"):
+		return &fileSource{path: strings.TrimPrefix(cfg.URL, "file://")}, nil
+	}
+	// A single letter before the colon is a Windows drive, not a scheme.
+	if scheme, _, ok := strings.Cut(cfg.URL, ":"); ok && len(scheme) > 1 && urlSchemePattern.MatchString(scheme) {
+		return nil, fmt.Errorf("unsupported calendar url scheme %q", scheme)
+	}
+	return &fileSource{path: cfg.URL}, nil
+}
+
+// fetchSource fetches the iCal data from the backend described by cfg.
+func fetchSource(cfg SourceConfig) (*ical.Calendar, error) {
+	src, err := newEventSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return src.Fetch()
+}
+
+// loadCalendar fetches the iCal data of a calendar from its source.
+func loadCalendar(name, rawURL string) (*ical.Calendar, error) {
+	src, err := sourceFor(name, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return src.Fetch()
+}
+
+// httpSource fetches a published iCal feed over HTTP(S).
+type httpSource struct {
+	url      string
+	username string
+	password string
+}
+
+func (s *httpSource) Fetch() (*ical.Calendar, error) {
+	resp, err := doWithAuth(http.MethodGet, s.url, nil, nil, s.username, s.password)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http request %s: unexpected status %s", s.url, resp.Status)
+	}
+	return ical.ParseCalendar(resp.Body)
+}
+
+// fileSource reads an iCal file from the local file system.
+type fileSource struct {
+	path string
+}
+
+func (s *fileSource) Fetch() (*ical.Calendar, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ical.ParseCalendar(f)
+}
+
+// calDAVSource queries a CalDAV calendar collection with a REPORT calendar-query
+// restricted to a time range around now.
+type calDAVSource struct {
+	config SourceConfig
+	url    string
+}
+
+type calDAVMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+func (s *calDAVSource) Fetch() (*ical.Calendar, error) {
+	pastDays, futureDays := s.config.PastDays, s.config.FutureDays
+	if pastDays <= 0 {
+		pastDays = defaultCalDAVPastDays
+	}
+	if futureDays <= 0 {
+		futureDays = defaultCalDAVFutureDays
+	}
+	now := time.Now().UTC()
+	body := calDAVQuery(now.AddDate(0, 0, -pastDays), now.AddDate(0, 0, futureDays))
+	headers := http.Header{
+		"Depth":        {"1"},
+		"Content-Type": {"application/xml; charset=utf-8"},
+	}
+	resp, err := doWithAuth("REPORT", s.url, body, headers, s.config.Username, s.config.Password)
+	if err != nil {
+		return nil, fmt.Errorf("caldav report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("caldav report %s: unexpected status %s", s.url, resp.Status)
+	}
+	var ms calDAVMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("caldav report: decode multistatus: %w", err)
+	}
+
+	cal := ical.NewCalendar()
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstat {
+			if ps.Prop.CalendarData == "" {
+				continue
+			}
+			part, err := ical.ParseCalendar(strings.NewReader(ps.Prop.CalendarData))
+			if err != nil {
+				slog.Warn("skip unparseable caldav object", "href", r.Href, "err", err)
+				continue
+			}
+			for _, e := range part.Events() {
+				cal.AddVEvent(e)
+			}
+		}
+	}
+	return cal, nil
+}
+
+// calDAVQuery builds the calendar-query REPORT body for VEVENTs overlapping [start, end).
+func calDAVQuery(start, end time.Time) []byte {
+	const layout = "20060102T150405Z"
+	return []byte(`<?xml version="1.0" encoding="utf-8" ?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data/>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="` + start.UTC().Format(layout) + `" end="` + end.UTC().Format(layout) + `"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`)
+}
+
+// doWithAuth sends a request without credentials. If the server asks for authentication,
+// the request is repeated with a digest response or, if only basic authentication is
+// offered, with basic credentials.
+func doWithAuth(method, rawURL string, body []byte, headers http.Header, username, password string) (*http.Response, error) {
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequest(method, rawURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range headers {
+			req.Header[k] = v
+		}
+		req.Header.Set("User-Agent", "ytc-server")
+		return req, nil
+	}
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := sourceClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || username == "" {
+		return resp, err
+	}
+	var digest, basic bool
+	var challenge string
+	for _, c := range resp.Header.Values("WWW-Authenticate") {
+		switch {
+		case strings.HasPrefix(strings.ToLower(c), "digest "):
+			digest, challenge = true, c
+		case strings.EqualFold(c, "basic") || strings.HasPrefix(strings.ToLower(c), "basic "):
+			basic = true
+		}
+	}
+	if !digest && !basic {
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	req, err = newRequest()
+	if err != nil {
+		return nil, err
+	}
+	if digest {
+		auth, err := digestAuthorization(challenge, method, req.URL, username, password)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", auth)
+	} else {
+		req.SetBasicAuth(username, password)
+	}
+	return sourceClient.Do(req)
+}
+
+// digestAuthorization computes the Authorization header for an RFC 7616 digest challenge.
+func digestAuthorization(challenge, method string, u *url.URL, username, password string) (string, error) {
+	params := parseAuthParams(challenge[len("digest "):])
+	realm, nonce := params["realm"], params["nonce"]
+	if nonce == "" {
+		return "", fmt.Errorf("digest challenge without nonce")
+	}
+	algorithm := params["algorithm"]
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "", "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	h := func(s string) string {
+		sum := newHash()
+		io.WriteString(sum, s)
+		return hex.EncodeToString(sum.Sum(nil))
+	}
+
+	cnonceBytes := make([]byte, 8)
+	if _, err := rand.Read(cnonceBytes); err != nil {
+		return "", err
+	}
+	cnonce := hex.EncodeToString(cnonceBytes)
+	const nc = "00000001"
+	uri := u.RequestURI()
+
+	ha1 := h(username + ":" + realm + ":" + password)
+	if strings.HasSuffix(strings.ToUpper(algorithm), "-SESS") {
+		ha1 = h(ha1 + ":" + nonce + ":" + cnonce)
+	}
+	ha2 := h(method + ":" + uri)
+
+	qop := ""
+	for _, q := range strings.Split(params["qop"], ",") {
+		if strings.TrimSpace(q) == "auth" {
+			qop = "auth"
+		}
+	}
+	var response string
+	if qop != "" {
+		response = h(ha1 + ":" + nonce + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + nonce + ":" + ha2)
+	}
+
+	parts := []string{
+		fmt.Sprintf(`username="%s"`, username),
+		fmt.Sprintf(`realm="%s"`, realm),
+		fmt.Sprintf(`nonce="%s"`, nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf(`response="%s"`, response),
+	}
+	if algorithm != "" {
+		parts = append(parts, "algorithm="+algorithm)
+	}
+	if qop != "" {
+		parts = append(parts, "qop="+qop, "nc="+nc, fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+	if opaque, ok := params["opaque"]; ok {
+		parts = append(parts, fmt.Sprintf(`opaque="%s"`, opaque))
+	}
+	return "Digest " + strings.Join(parts, ", "), nil
+}
+
+// parseAuthParams parses the comma separated key=value pairs of a WWW-Authenticate header.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				value, s = s, ""
+			} else {
+				value, s = s[:end], s[end:]
+			}
+		}
+		params[key] = strings.TrimSpace(value)
+	}
+	return params
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+// memorySource serves a fixed calendar for tests.
+type memorySource struct {
+	cal *ical.Calendar
+	err error
+}
+
+func (s *memorySource) Fetch() (*ical.Calendar, error) { return s.cal, s.err }
+
+func icsWithEvent(uid, summary string, start time.Time) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\nBEGIN:VEVENT\r\nUID:" + uid +
+		"\r\nSUMMARY:" + summary +
+		"\r\nDTSTART:" + start.UTC().Format("20060102T150405Z") +
+		"\r\nDTEND:" + start.Add(time.Hour).UTC().Format("20060102T150405Z") +
+		"\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+}
+
+func TestMemorySourceInjection(t *testing.T) {
+	cal, err := ical.ParseCalendar(strings.NewReader(icsWithEvent("m1", "Injected", time.Now().Add(24*time.Hour))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calendarURLs = map[string]string{"sonderkurse": "webcal://unused"}
+	registerEventSource("sonderkurse", &memorySource{cal: cal})
+	defer delete(eventSources, "sonderkurse")
+
+	events := fetchEventsForCalendar("sonderkurse", time.Now(), time.Now().AddDate(0, 0, expansionDays))
+	if len(events) != 1 || events[0].Summary != "Injected" {
+		t.Errorf("expected injected event, got %+v", events)
+	}
+}
+
+func TestNewEventSource(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"webcal://example.org/a.ics", "*app.httpSource"},
+		{"https://example.org/a.ics", "*app.httpSource"},
+		{"file:///tmp/a.ics", "*app.fileSource"},
+		{"testdata/a.ics", "*app.fileSource"},
+		{"caldav://example.org/cal/", "*app.calDAVSource"},
+	}
+	for _, tt := range tests {
+		src, err := newEventSource(SourceConfig{URL: tt.url})
+		if err != nil {
+			t.Errorf("newEventSource(%q): %v", tt.url, err)
+			continue
+		}
+		if got := fmt.Sprintf("%T", src); got != tt.want {
+			t.Errorf("newEventSource(%q) = %s; want %s", tt.url, got, tt.want)
+		}
+	}
+	if src, _ := newEventSource(SourceConfig{URL: "webcal://example.org/a.ics"}); src.(*httpSource).url != "https://example.org/a.ics" {
+		t.Errorf("expected webcal to be fetched over https, got %q", src.(*httpSource).url)
+	}
+	for _, url := range []string{"htps://example.org/a.ics", "caldav:example.org/cal/"} {
+		if _, err := newEventSource(SourceConfig{URL: url}); err == nil {
+			t.Errorf("expected %q to be rejected", url)
+		}
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kurse.ics")
+	if err := os.WriteFile(path, []byte(icsWithEvent("f1", "Aus Datei", time.Now())), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cal, err := fetchSource(SourceConfig{URL: "file://" + path})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(cal.Events()) != 1 {
+		t.Errorf("expected 1 event, got %d", len(cal.Events()))
+	}
+}
+
+func TestCalDAVSourceDigestAuth(t *testing.T) {
+	const realm, nonce = "caldav", "abc123"
+	start := time.Now().Add(48 * time.Hour)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Digest ") {
+			w.Header().Add("WWW-Authenticate", `Digest realm="`+realm+`", nonce="`+nonce+`", qop="auth", algorithm=MD5`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		p := parseAuthParams(strings.TrimPrefix(auth, "Digest "))
+		md5hex := func(s string) string { sum := md5.Sum([]byte(s)); return hex.EncodeToString(sum[:]) }
+		ha1 := md5hex("user:" + realm + ":pass")
+		ha2 := md5hex(r.Method + ":" + p["uri"])
+		if p["response"] != md5hex(ha1+":"+nonce+":"+p["nc"]+":"+p["cnonce"]+":auth:"+ha2) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != "REPORT" || r.Header.Get("Depth") != "1" || !strings.Contains(string(body), "<C:time-range start=") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">
+  <d:response><d:href>/cal/1.ics</d:href><d:propstat><d:prop><cal:calendar-data>%s</cal:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>
+  <d:response><d:href>/cal/2.ics</d:href><d:propstat><d:prop><cal:calendar-data>%s</cal:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>
+</d:multistatus>`, icsWithEvent("c1", "Privat 1", start), icsWithEvent("c2", "Privat 2", start))
+	}))
+	defer srv.Close()
+
+	cal, err := fetchSource(SourceConfig{
+		URL:      "caldav+http://" + strings.TrimPrefix(srv.URL, "http://") + "/cal/",
+		Username: "user",
+		Password: "pass",
+	})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(cal.Events()) != 2 {
+		t.Errorf("expected 2 events from the REPORT response, got %d", len(cal.Events()))
+	}
+}
+
+func TestParseAuthParams(t *testing.T) {
+	p := parseAuthParams(`realm="a, b", nonce=xyz, qop="auth,auth-int"`)
+	if p["realm"] != "a, b" || p["nonce"] != "xyz" || p["qop"] != "auth,auth-int" {
+		t.Errorf("unexpected params %v", p)
+	}
+}
+
+func TestHTTPSourceBasicAuthAfterChallenge(t *testing.T) {
+	var unauthenticated int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok {
+			unauthenticated++
+			w.Header().Set("WWW-Authenticate", `Basic realm="kurse"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, icsWithEvent("b1", "Geschützt", time.Now()))
+	}))
+	defer srv.Close()
+
+	cal, err := fetchSource(SourceConfig{URL: srv.URL, Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if unauthenticated != 1 || len(cal.Events()) != 1 {
+		t.Errorf("expected credentials only after the challenge, got %d unauthenticated requests and %d events", unauthenticated, len(cal.Events()))
+	}
+}
+
+func TestApplySourcesAddsCalendar(t *testing.T) {
+	urls := calendarURLs
+	calendarURLs = map[string]string{"sonderkurse": "", "wochenkurse": ""}
+	t.Cleanup(func() {
+		calendarURLs = urls
+		delete(eventSources, "qigong")
+		delete(calendarColors, "qigong")
+		delete(calendarBtnClasses, "qigong")
+	})
+	applySources(map[string]EventSource{"qigong": &memorySource{cal: ical.NewCalendar()}})
+
+	if got := strings.Join(calendarNames(), ","); got != "wochenkurse,sonderkurse,qigong" {
+		t.Errorf("expected built-in calendars first, got %s", got)
+	}
+	if calendarColors["qigong"] == "" || calendarBtnClasses["qigong"] == "" {
+		t.Error("expected a colour and button class for the added calendar")
+	}
+}
@@ -0,0 +1,102 @@
+// Renders the Yang Tai Chi Hamburg schedule into a partner page.
+//
+//   <div id="ytc-calendar"></div>
+//   <script src="https://example.org/embed/calendar.js" data-calendar="wochenkurse"
+//           data-days="14" data-limit="5" data-lang="de" data-accent="#198754" async></script>
+//
+// data-target names the element to render into; without it the list is inserted after the script.
+(function () {
+  var script = document.currentScript;
+  if (!script) {
+    return;
+  }
+  var data = script.dataset;
+  var base = new URL(script.src).origin;
+  var lang = data.lang === 'en' ? 'en' : 'de';
+  var texts = {
+    de: { cancelled: 'entfällt', closed: 'Geschlossen', none: 'Zurzeit sind keine Termine geplant.', more: 'Alle Termine' },
+    en: { cancelled: 'cancelled', closed: 'Closed', none: 'No dates are planned at the moment.', more: 'All dates' }
+  }[lang];
+
+  var target = data.target && document.getElementById(data.target);
+  if (!target) {
+    target = document.createElement('div');
+    script.parentNode.insertBefore(target, script.nextSibling);
+  }
+  target.classList.add('ytc-calendar');
+  target.style.setProperty('--ytc-accent', /^#[0-9a-fA-F]{3,8}$/.test(data.accent || '') ? data.accent : '#0d6efd');
+
+  var params = new URLSearchParams({ lang: lang, limit: data.limit || '10' });
+  if (data.calendar) {
+    params.set('calendar', data.calendar);
+  }
+  if (data.days) {
+    params.set('days', data.days);
+  }
+
+  var dateTime = new Intl.DateTimeFormat(lang, { weekday: 'short', day: 'numeric', month: 'short', hour: 'numeric', minute: '2-digit' });
+  var date = new Intl.DateTimeFormat(lang, { weekday: 'short', day: 'numeric', month: 'short', timeZone: 'UTC' });
+
+  function el(tag, className, text) {
+    var node = document.createElement(tag);
+    if (className) {
+      node.className = className;
+    }
+    if (text) {
+      node.textContent = text;
+    }
+    return node;
+  }
+
+  function render(events) {
+    target.textContent = '';
+    if (!events.length) {
+      target.appendChild(el('p', 'ytc-empty', texts.none));
+    }
+    var list = el('ul', 'ytc-events');
+    list.style.listStyle = 'none';
+    list.style.padding = '0';
+    events.forEach(function (e) {
+      var item = el('li', 'ytc-event' + (e.cancelled ? ' ytc-cancelled' : ''));
+      item.style.padding = '0.4em 0';
+      var when = e.allDay ? date.format(new Date(e.start)) : dateTime.format(new Date(e.start));
+      if (e.closure) {
+        when = texts.closed + ': ' + when;
+      }
+      var whenNode = el('strong', 'ytc-when', when);
+      whenNode.style.color = 'var(--ytc-accent)';
+      item.appendChild(whenNode);
+      item.appendChild(document.createElement('br'));
+      var summary = el('span', 'ytc-summary', e.summary);
+      if (e.cancelled) {
+        summary.style.textDecoration = 'line-through';
+        summary.title = e.cancelReason || texts.cancelled;
+      }
+      item.appendChild(summary);
+      if (e.location) {
+        item.appendChild(document.createElement('br'));
+        item.appendChild(el('small', 'ytc-location', e.location));
+      }
+      list.appendChild(item);
+    });
+    target.appendChild(list);
+    var more = el('a', 'ytc-more', texts.more);
+    more.href = base + '/calendar?' + new URLSearchParams({ lang: lang, calendar: data.calendar || '' });
+    more.target = '_blank';
+    more.rel = 'noopener';
+    more.style.color = 'var(--ytc-accent)';
+    target.appendChild(more);
+  }
+
+  fetch(base + '/api/events?' + params)
+    .then(function (res) {
+      if (!res.ok) {
+        throw new Error(res.status);
+      }
+      return res.json();
+    })
+    .then(render)
+    .catch(function () {
+      target.textContent = texts.none;
+    });
+})();
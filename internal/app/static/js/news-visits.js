@@ -0,0 +1,41 @@
+// Highlights news published since the visitor's last visit to the news page. The time of that
+// visit is kept in localStorage only; no cookie is set.
+(function () {
+  "use strict";
+  var key = "ytc-news-last-visit";
+  var lastVisit;
+  try {
+    lastVisit = window.localStorage.getItem(key);
+  } catch (e) {
+    return; // storage disabled
+  }
+  var since = lastVisit ? Date.parse(lastVisit) : NaN;
+
+  var items = document.querySelectorAll("[data-news-published]");
+  if (items.length > 0) {
+    items.forEach(function (item) {
+      var marker = item.querySelector(".news-new-marker");
+      if (marker && !isNaN(since) && Date.parse(item.getAttribute("data-news-published")) > since) {
+        marker.classList.remove("d-none");
+      }
+    });
+    window.localStorage.setItem(key, new Date().toISOString());
+    return;
+  }
+
+  var badge = document.getElementById("news-new-count");
+  if (!badge || isNaN(since) || !window.fetch) {
+    return;
+  }
+  var url = "/api/news/latest?lang=" + encodeURIComponent(badge.getAttribute("data-lang")) +
+    "&since=" + encodeURIComponent(new Date(since).toISOString());
+  fetch(url)
+    .then(function (resp) { return resp.ok ? resp.json() : null; })
+    .then(function (data) {
+      if (data && data.count > 0) {
+        badge.textContent = data.count > 9 ? "9+" : String(data.count);
+        badge.classList.remove("d-none");
+      }
+    })
+    .catch(function () {});
+})();
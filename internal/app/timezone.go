@@ -0,0 +1,120 @@
+package app
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+	_ "time/tzdata" // visitors may pick any zone, whatever the host has installed
+
+	ical "github.com/arran4/golang-ical"
+
+	"github.com/WillyWinkel/ytc/internal/utils"
+)
+
+const timeZoneCookie = "ytc_tz"
+
+// timeZones are offered in the time zone picker in addition to the visitor's browser zone.
+var timeZones = []string{
+	"Europe/Berlin",
+	"Europe/London",
+	"Europe/Lisbon",
+	"Europe/Athens",
+	"Europe/Moscow",
+	"America/New_York",
+	"America/Chicago",
+	"America/Denver",
+	"America/Los_Angeles",
+	"America/Sao_Paulo",
+	"Asia/Dubai",
+	"Asia/Kolkata",
+	"Asia/Shanghai",
+	"Asia/Tokyo",
+	"Australia/Sydney",
+	"UTC",
+}
+
+// templateBases are never executed so that templateFor can clone them with other format functions.
+var templateBases map[string]*template.Template
+
+// zoneTemplates caches the template sets bound to a visitor time zone by "lang|zone".
+var zoneTemplates sync.Map
+
+// getLocation returns the visitor's time zone. A valid tz query parameter is remembered in a
+// cookie; without one the cookie or the configured display time zone is used.
+func getLocation(w http.ResponseWriter, r *http.Request) *time.Location {
+	if loc, ok := queryLocation(r); ok {
+		http.SetCookie(w, &http.Cookie{
+			Name:     timeZoneCookie,
+			Value:    loc.String(),
+			Path:     "/",
+			MaxAge:   365 * 24 * 60 * 60,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return loc
+	}
+	if c, err := r.Cookie(timeZoneCookie); err == nil && c.Value != "" {
+		if loc, err := time.LoadLocation(c.Value); err == nil {
+			return loc
+		}
+	}
+	return displayLocation
+}
+
+// queryLocation returns the time zone given with the tz query parameter, if it is valid.
+func queryLocation(r *http.Request) (*time.Location, bool) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		slog.Debug("ignore unknown time zone", "tz", tz)
+		return nil, false
+	}
+	return loc, true
+}
+
+// templateFor returns the templates of lang with event times formatted in loc.
+func templateFor(lang string, loc *time.Location) (*template.Template, bool) {
+	if loc == nil || loc.String() == displayLocation.String() {
+		tmpl, ok := templatesByLang[lang]
+		return tmpl, ok
+	}
+	key := lang + "|" + loc.String()
+	if tmpl, ok := zoneTemplates.Load(key); ok {
+		return tmpl.(*template.Template), true
+	}
+	base, ok := templateBases[lang]
+	if !ok {
+		return nil, false
+	}
+	tmpl, err := base.Clone()
+	if err != nil {
+		slog.Error("clone templates", "lang", lang, "err", err)
+		return nil, false
+	}
+	tmpl.Funcs(formatFuncs(lang, loc))
+	actual, _ := zoneTemplates.LoadOrStore(key, tmpl)
+	return actual.(*template.Template), true
+}
+
+// parseEventTime parses a DTSTART or DTEND property. Times with a TZID are read in that zone and
+// floating times in the display time zone; dates and UTC times are returned in UTC.
+func parseEventTime(prop *ical.IANAProperty) time.Time {
+	t, _ := utils.ParseICalTimeToHuman(prop.Value)
+	if t.IsZero() || isDateValue(prop) || len(prop.Value) > 0 && prop.Value[len(prop.Value)-1] == 'Z' {
+		return t
+	}
+	loc := displayLocation
+	if tzid := prop.ICalParameters[string(ical.ParameterTzid)]; len(tzid) > 0 {
+		l, err := time.LoadLocation(tzid[0])
+		if err != nil {
+			slog.Warn("unknown TZID", "tzid", tzid[0], "err", err)
+			return t
+		}
+		loc = l
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+func TestParseEventTime(t *testing.T) {
+	event := ical.NewEvent("tz")
+	event.SetProperty(ical.ComponentPropertyDtStart, "20250512T180000", ical.WithTZID("Europe/Berlin"))
+	event.SetProperty(ical.ComponentPropertyDtEnd, "20250512T193000")
+	if got := parseEventTime(event.GetProperty(ical.ComponentPropertyDtStart)); !got.Equal(time.Date(2025, 5, 12, 16, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected TZID time in Berlin, got %v", got)
+	}
+	if got := parseEventTime(event.GetProperty(ical.ComponentPropertyDtEnd)); !got.Equal(time.Date(2025, 5, 12, 19, 30, 0, 0, displayLocation)) {
+		t.Errorf("expected floating time in display zone, got %v", got)
+	}
+	event.SetProperty(ical.ComponentPropertyDtEnd, "20250513", ical.WithValue("DATE"))
+	if got := parseEventTime(event.GetProperty(ical.ComponentPropertyDtEnd)); got.Location() != time.UTC || got.Hour() != 0 {
+		t.Errorf("expected date at UTC midnight, got %v", got)
+	}
+}
+
+func TestGetLocation(t *testing.T) {
+	w := httptest.NewRecorder()
+	loc := getLocation(w, httptest.NewRequest("GET", "/calendar?tz=America/New_York", nil))
+	if loc.String() != "America/New_York" {
+		t.Errorf("expected zone from query, got %v", loc)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != timeZoneCookie || cookies[0].Value != "America/New_York" {
+		t.Fatalf("expected time zone cookie, got %+v", cookies)
+	}
+
+	r := httptest.NewRequest("GET", "/calendar?tz=Mars/Olympus", nil)
+	r.AddCookie(cookies[0])
+	if loc := getLocation(httptest.NewRecorder(), r); loc.String() != "America/New_York" {
+		t.Errorf("expected zone from cookie, got %v", loc)
+	}
+	if loc := getLocation(httptest.NewRecorder(), httptest.NewRequest("GET", "/calendar", nil)); loc != displayLocation {
+		t.Errorf("expected display zone, got %v", loc)
+	}
+}
+
+func TestVisitorTimeZoneInOutputs(t *testing.T) {
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	start := time.Date(2025, 5, 12, 18, 0, 0, 0, time.UTC)
+	clock = func() time.Time { return start.AddDate(0, 0, -2) }
+	defer func() { clock = time.Now }()
+	calendarURLs = map[string]string{"sonderkurse": ""}
+	useTestCalendar(t, "sonderkurse", icsWithEvent("s1", "Workshop", start))
+
+	w := httptest.NewRecorder()
+	calendarHandler(w, httptest.NewRequest("GET", "/calendar?calendar=sonderkurse&lang=en&tz=America/New_York", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "Mon, May 12, 2:00 PM") || !strings.Contains(body, `<option value="America/New_York" selected>`) {
+		t.Errorf("expected event time in New York, got %q", body)
+	}
+
+	w = httptest.NewRecorder()
+	calendarHandler(w, httptest.NewRequest("GET", "/calendar?calendar=sonderkurse&lang=en", nil))
+	if !strings.Contains(w.Body.String(), "Mon, May 12, 8:00 PM") {
+		t.Error("expected event time in display zone without tz")
+	}
+
+	w = httptest.NewRecorder()
+	eventsAPIHandler(w, httptest.NewRequest("GET", "/api/events?calendar=sonderkurse&tz=Europe/Berlin", nil))
+	if !strings.Contains(w.Body.String(), `"start":"2025-05-12T20:00:00+02:00"`) {
+		t.Errorf("expected explicit offset in JSON, got %q", w.Body.String())
+	}
+}
+
+func TestDefaultDisplayZoneKeepsWallClock(t *testing.T) {
+	if displayLocation.String() != defaultTimeZone {
+		t.Fatalf("expected %s without a configured zone, got %v", defaultTimeZone, displayLocation)
+	}
+	supportedLangs = []string{"en", "de"}
+	loadTemplates()
+	clock = func() time.Time { return time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC) }
+	defer func() { clock = time.Now }()
+	calendarURLs = map[string]string{"sonderkurse": ""}
+	useTestCalendar(t, "sonderkurse", "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+		"BEGIN:VEVENT\r\nUID:b1\r\nSUMMARY:Abendkurs\r\nDTSTART;TZID=Europe/Berlin:20250512T180000\r\n"+
+		"DTEND;TZID=Europe/Berlin:20250512T193000\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n")
+
+	w := httptest.NewRecorder()
+	calendarHandler(w, httptest.NewRequest("GET", "/calendar?calendar=sonderkurse&lang=de", nil))
+	if body := w.Body.String(); !strings.Contains(body, "Mo., 12. Mai, 18:00") || !strings.Contains(body, "Mo., 12. Mai, 19:30") {
+		t.Errorf("expected the Berlin wall-clock time, got %q", body)
+	}
+}
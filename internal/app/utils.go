@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 )
 
@@ -17,6 +18,8 @@ var templatesFS embed.FS
 // loadTemplates parses templates for all supported languages and stores them in templatesByLang.
 func loadTemplates() {
 	templatesByLang = make(map[string]*template.Template)
+	templateBases = make(map[string]*template.Template)
+	zoneTemplates.Clear()
 	funcMap := template.FuncMap{
 		"title": func(s string) string { return strings.ToTitle(s) },
 		"dict": func(values ...interface{}) map[string]interface{} {
@@ -31,6 +34,8 @@ func loadTemplates() {
 			return dict
 		},
 		"safeURL": func(u string) template.URL { return template.URL(u) },
+		"inList":  func(s string, list []string) bool { return slices.Contains(list, s) },
+		"add":     func(a, b int) int { return a + b },
 	}
 	for _, lang := range supportedLangs {
 		pattern := "static/templates/" + lang + "/*.html"
@@ -39,7 +44,7 @@ func loadTemplates() {
 			slog.Error("failed to glob templates", "lang", lang, "err", err)
 			continue
 		}
-		tmpl := template.New("").Funcs(funcMap)
+		tmpl := template.New("").Funcs(funcMap).Funcs(formatFuncs(lang, displayLocation))
 		if len(files) == 0 {
 			slog.Error("no templates found", "lang", lang)
 			continue
@@ -49,16 +54,27 @@ func loadTemplates() {
 			continue
 		}
 		templatesByLang[lang] = tmpl
+		if templateBases[lang], err = tmpl.Clone(); err != nil {
+			slog.Error("failed to clone templates", "lang", lang, "err", err)
+		}
 	}
 }
 
 // getLang returns the requested language if supported, otherwise the default.
 func getLang(r *http.Request) string {
 	lang := r.URL.Query().Get("lang")
+	if isSupportedLang(lang) {
+		return lang
+	}
+	return defaultLang
+}
+
+// isSupportedLang reports whether lang is one of the supported languages.
+func isSupportedLang(lang string) bool {
 	for _, l := range supportedLangs {
 		if lang == l {
-			return l
+			return true
 		}
 	}
-	return defaultLang
+	return false
 }
@@ -0,0 +1,275 @@
+package app
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	webhookSignatureHeader = "X-YTC-Signature"
+	webhookEventHeader     = "X-YTC-Event"
+	webhookDeliveryHeader  = "X-YTC-Delivery"
+	webhookMaxAttempts     = 5
+	maxWebhookDeliveries   = 200
+	// webhookQueueSize bounds the payloads waiting per endpoint; further ones are dropped.
+	webhookQueueSize = 100
+)
+
+// webhookBackoff is the delay before the first retry; it doubles with every further attempt.
+var webhookBackoff = 2 * time.Second
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// WebhookPayload is the JSON body posted to webhook endpoints for every change.
+type WebhookPayload struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	Change    Change    `json:"change"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// WebhookDelivery records the outcome of delivering one payload to one endpoint.
+type WebhookDelivery struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Event      string    `json:"event"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Delivered  bool      `json:"delivered"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// webhookLog keeps a bounded history of deliveries and optionally appends them to a JSON lines file.
+type webhookLog struct {
+	mu         sync.Mutex
+	path       string
+	deliveries []WebhookDelivery // newest first
+}
+
+var webhookDeliveries = &webhookLog{}
+
+func (l *webhookLog) add(d WebhookDelivery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.deliveries = append([]WebhookDelivery{d}, l.deliveries...)
+	if len(l.deliveries) > maxWebhookDeliveries {
+		l.deliveries = l.deliveries[:maxWebhookDeliveries]
+	}
+	if l.path == "" {
+		return
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Error("open webhook log", "path", l.path, "err", err)
+		return
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(d); err != nil {
+		slog.Error("write webhook log", "path", l.path, "err", err)
+	}
+}
+
+// setupWebhooks subscribes the configured webhook endpoints to detected schedule changes.
+func setupWebhooks(cfg Config) {
+	webhookDeliveries.path = cfg.WebhookLog
+	if len(cfg.Webhooks) == 0 {
+		return
+	}
+	queues := make([]*webhookQueue, len(cfg.Webhooks))
+	for i, wh := range cfg.Webhooks {
+		queues[i] = newWebhookQueue(wh, webhookQueueSize)
+		go queues[i].run()
+	}
+	calendarChanges.subscribe(func(changes []Change) {
+		for _, q := range queues {
+			for _, c := range changes {
+				if q.hook.wants(c) {
+					q.enqueue(newWebhookPayload(c))
+				}
+			}
+		}
+	})
+	slog.Info("Webhooks enabled", "endpoints", len(queues))
+}
+
+// webhookQueue holds the payloads waiting for delivery to one endpoint; run delivers them one
+// after another.
+type webhookQueue struct {
+	hook     WebhookConfig
+	payloads chan WebhookPayload
+}
+
+func newWebhookQueue(wh WebhookConfig, size int) *webhookQueue {
+	return &webhookQueue{hook: wh, payloads: make(chan WebhookPayload, size)}
+}
+
+// enqueue adds the payload to the queue. If the queue is full the payload is dropped and
+// logged as a failed delivery.
+func (q *webhookQueue) enqueue(payload WebhookPayload) bool {
+	select {
+	case q.payloads <- payload:
+		return true
+	default:
+		slog.Error("webhook queue full, dropping delivery", "url", q.hook.URL, "event", payload.Event)
+		webhookDeliveries.add(WebhookDelivery{ID: payload.ID, URL: q.hook.URL, Event: payload.Event, Error: "queue full", FinishedAt: time.Now()})
+		return false
+	}
+}
+
+// run delivers the queued payloads until the queue is closed.
+func (q *webhookQueue) run() {
+	for payload := range q.payloads {
+		deliverWebhook(q.hook, payload)
+	}
+}
+
+// wants reports whether the endpoint is interested in the change.
+func (wh WebhookConfig) wants(c Change) bool {
+	if len(wh.Calendars) > 0 && !slices.Contains(wh.Calendars, c.Calendar) {
+		return false
+	}
+	if len(wh.Kinds) > 0 && !slices.Contains(wh.Kinds, c.Kind) {
+		return false
+	}
+	return true
+}
+
+// webhookEvent names a change as "<calendar|news>.<kind>", e.g. "calendar.rescheduled".
+func webhookEvent(c Change) string {
+	if _, isNews := newsURLs[c.Calendar]; isNews {
+		return "news." + string(c.Kind)
+	}
+	return "calendar." + string(c.Kind)
+}
+
+func newWebhookPayload(c Change) WebhookPayload {
+	return WebhookPayload{
+		ID:        newDeliveryID(),
+		Event:     webhookEvent(c),
+		Change:    c,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// SignWebhookPayload returns the signature header value for body: "sha256=" followed by the hex HMAC-SHA256.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks a signature header value produced by SignWebhookPayload.
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhookPayload(secret, body)), []byte(signature))
+}
+
+// deliverWebhook posts the payload to the endpoint, retrying with exponential backoff.
+func deliverWebhook(wh WebhookConfig, payload WebhookPayload) WebhookDelivery {
+	delivery := WebhookDelivery{ID: payload.ID, URL: wh.URL, Event: payload.Event}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("encode webhook payload", "err", err)
+		delivery.Error = err.Error()
+		delivery.FinishedAt = time.Now()
+		webhookDeliveries.add(delivery)
+		return delivery
+	}
+	backoff := webhookBackoff
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		delivery.Attempts = attempt
+		delivery.StatusCode, err = postWebhook(wh, payload, body)
+		if err == nil {
+			delivery.Delivered = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+		slog.Warn("webhook delivery failed", "url", wh.URL, "event", payload.Event, "attempt", attempt, "err", err)
+		if attempt < webhookMaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	delivery.FinishedAt = time.Now()
+	if delivery.Delivered {
+		slog.Info("webhook delivered", "url", wh.URL, "event", payload.Event, "attempts", delivery.Attempts)
+	} else {
+		slog.Error("webhook delivery gave up", "url", wh.URL, "event", payload.Event, "attempts", delivery.Attempts)
+	}
+	webhookDeliveries.add(delivery)
+	return delivery
+}
+
+func postWebhook(wh WebhookConfig, payload WebhookPayload, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ytc-server")
+	req.Header.Set(webhookEventHeader, payload.Event)
+	req.Header.Set(webhookDeliveryHeader, payload.ID)
+	if wh.Secret != "" {
+		req.Header.Set(webhookSignatureHeader, SignWebhookPayload(wh.Secret, body))
+	}
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// WebhookReceiver returns a handler that verifies incoming webhook signatures and writes
+// each payload to out. It is meant for testing webhook delivery locally.
+func WebhookReceiver(secret string, out io.Writer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		if secret != "" && !VerifyWebhookSignature(secret, body, r.Header.Get(webhookSignatureHeader)) {
+			slog.Warn("rejected webhook with invalid signature", "delivery", r.Header.Get(webhookDeliveryHeader))
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			return
+		}
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, body, "", "  "); err != nil {
+			pretty.Reset()
+			pretty.Write(body)
+		}
+		fmt.Fprintf(out, "%s %s\n%s\n", r.Header.Get(webhookEventHeader), r.Header.Get(webhookDeliveryHeader), strings.TrimSpace(pretty.String()))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	body := []byte(`{"event":"calendar.added"}`)
+	sig := SignWebhookPayload("secret", body)
+	if !strings.HasPrefix(sig, "sha256=") {
+		t.Errorf("expected sha256= prefix, got %q", sig)
+	}
+	if !VerifyWebhookSignature("secret", body, sig) {
+		t.Error("expected signature to verify")
+	}
+	if VerifyWebhookSignature("other", body, sig) {
+		t.Error("expected signature with wrong secret to fail")
+	}
+}
+
+func TestDeliverWebhookRetries(t *testing.T) {
+	backoff := webhookBackoff
+	webhookBackoff = time.Millisecond
+	t.Cleanup(func() { webhookBackoff = backoff })
+	var calls atomic.Int32
+	var received WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhookSignature("s3cret", body, r.Header.Get(webhookSignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	change := Change{Kind: ChangeCancelled, Calendar: "sonderkurse", UID: "u1", Summary: "Workshop"}
+	d := deliverWebhook(WebhookConfig{URL: srv.URL, Secret: "s3cret"}, newWebhookPayload(change))
+	if !d.Delivered || d.Attempts != 3 {
+		t.Errorf("expected delivery after 3 attempts, got %+v", d)
+	}
+	if received.Event != "calendar.cancelled" || received.Change.UID != "u1" {
+		t.Errorf("unexpected payload %+v", received)
+	}
+	if webhookDeliveries.deliveries[0].ID != d.ID {
+		t.Error("expected delivery to be logged")
+	}
+}
+
+func TestWebhookQueueDropsWhenFull(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	q := newWebhookQueue(WebhookConfig{URL: srv.URL}, 2)
+	change := Change{Kind: ChangeAdded, Calendar: "sonderkurse", UID: "u1"}
+	for i, want := range []bool{true, true, false} {
+		if got := q.enqueue(newWebhookPayload(change)); got != want {
+			t.Errorf("payload %d: queued = %v; want %v", i, got, want)
+		}
+	}
+	if d := webhookDeliveries.deliveries[0]; d.Delivered || d.Error != "queue full" {
+		t.Errorf("expected the dropped payload to be logged, got %+v", d)
+	}
+	close(q.payloads)
+	q.run()
+	if calls.Load() != 2 {
+		t.Errorf("expected the queued payloads to be delivered, got %d calls", calls.Load())
+	}
+}
+
+func TestWebhookConfigWants(t *testing.T) {
+	wh := WebhookConfig{Calendars: []string{"news"}, Kinds: []ChangeKind{ChangeAdded}}
+	if !wh.wants(Change{Calendar: "news", Kind: ChangeAdded}) {
+		t.Error("expected matching change to be wanted")
+	}
+	if wh.wants(Change{Calendar: "news", Kind: ChangeRemoved}) || wh.wants(Change{Calendar: "wochenkurse", Kind: ChangeAdded}) {
+		t.Error("expected filtered changes to be skipped")
+	}
+	if !(WebhookConfig{}).wants(Change{Calendar: "x", Kind: ChangeRelocated}) {
+		t.Error("expected empty filters to accept all changes")
+	}
+}
+
+func TestWebhookReceiver(t *testing.T) {
+	var out bytes.Buffer
+	h := WebhookReceiver("secret", &out)
+	body := []byte(`{"event":"news.added"}`)
+
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set(webhookSignatureHeader, "sha256=bogus")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for bad signature, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set(webhookSignatureHeader, SignWebhookPayload("secret", body))
+	req.Header.Set(webhookEventHeader, "news.added")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent || !strings.Contains(out.String(), "news.added") {
+		t.Errorf("expected payload to be accepted and printed, got %d %q", w.Code, out.String())
+	}
+}
@@ -0,0 +1,79 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/WillyWinkel/ytc/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func calendarCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "calendar",
+		Short: "Check the configured calendars",
+	}
+	cmd.AddCommand(calendarConflictsCmd())
+	cmd.AddCommand(calendarLintCmd())
+	return cmd
+}
+
+func calendarConflictsCmd() *cobra.Command {
+	var (
+		days   int
+		format string
+	)
+	cmd := &cobra.Command{
+		Use:   "conflicts",
+		Short: "Report overlapping events that share a location or instructor",
+		Long: "Loads all configured calendars, expands recurring events and reports overlapping events " +
+			"that share a location or instructor. Exits with status 1 if conflicts are found.",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.LoadConfig(configFile); err != nil {
+				fmt.Println("Failed to load config:", err)
+				os.Exit(1)
+			}
+			conflicts, err := app.FindConflicts(days)
+			if err != nil {
+				fmt.Println("Failed to load calendars:", err)
+			}
+			if err := app.WriteConflicts(os.Stdout, conflicts, format); err != nil {
+				fmt.Println("Failed to write report:", err)
+				os.Exit(1)
+			}
+			if err != nil || len(conflicts) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", 90, "Number of days ahead to check")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table, json)")
+	return cmd
+}
+
+func calendarLintCmd() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "lint [source|file]...",
+		Short: "Report problems in calendar feeds",
+		Long: "Parses the given configured calendars, files or URLs (all configured calendars if none are given) " +
+			"and reports missing or duplicate UIDs, invalid dates, unknown TZIDs, DTEND before DTSTART and empty summaries. " +
+			"Exits with status 1 if errors are found.",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.LoadConfig(configFile); err != nil {
+				fmt.Println("Failed to load config:", err)
+				os.Exit(1)
+			}
+			issues := app.LintCalendars(args)
+			if err := app.WriteLintIssues(os.Stdout, issues, format); err != nil {
+				fmt.Println("Failed to write report:", err)
+				os.Exit(1)
+			}
+			if app.HasLintErrors(issues) {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table, json)")
+	return cmd
+}
@@ -0,0 +1,73 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/WillyWinkel/ytc/internal/app"
+	"github.com/WillyWinkel/ytc/internal/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slog"
+)
+
+func digestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Email digest of upcoming events",
+	}
+	cmd.AddCommand(digestSendCmd())
+	cmd.AddCommand(digestPreviewCmd())
+	return cmd
+}
+
+func digestSendCmd() *cobra.Command {
+	var dryRun string
+	cmd := &cobra.Command{
+		Use:   "send",
+		Short: "Send the digest to all configured subscribers",
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.SetupLogging(logfile)
+			if err := app.LoadConfig(configFile); err != nil {
+				slog.Error("Failed to load config", "err", err)
+				fmt.Println("Failed to load config:", err)
+				os.Exit(1)
+			}
+			if err := app.SendDigest(dryRun); err != nil {
+				slog.Error("Digest failed", "err", err)
+				fmt.Println("Digest failed:", err)
+				os.Exit(1)
+			}
+			if dryRun != "" {
+				fmt.Println("Digest written to", dryRun)
+				return
+			}
+			fmt.Println("Digest sent.")
+		},
+	}
+	cmd.Flags().StringVar(&dryRun, "dry-run", "", "Write the emails as .eml files to this directory instead of sending them")
+	return cmd
+}
+
+func digestPreviewCmd() *cobra.Command {
+	var (
+		lang   string
+		format string
+	)
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Print the digest for one language to stdout",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.LoadConfig(configFile); err != nil {
+				fmt.Println("Failed to load config:", err)
+				os.Exit(1)
+			}
+			if err := app.PreviewDigest(os.Stdout, lang, format); err != nil {
+				fmt.Println("Preview failed:", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&lang, "lang", "de", "Language of the digest (de, en)")
+	cmd.Flags().StringVar(&format, "format", "text", "Part to print (text, html)")
+	return cmd
+}
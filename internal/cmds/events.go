@@ -0,0 +1,48 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/WillyWinkel/ytc/internal/app"
+	"github.com/spf13/cobra"
+)
+
+func eventsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Show events without starting the server",
+	}
+	cmd.AddCommand(eventsListCmd())
+	return cmd
+}
+
+func eventsListCmd() *cobra.Command {
+	var (
+		calendar string
+		days     int
+		lang     string
+		format   string
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Print upcoming events",
+		Long: "Fetches the calendars like the calendar page does, including capacity overrides and closures, " +
+			"and prints the events of the next days as a table, JSON or iCal.",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := app.LoadConfig(configFile); err != nil {
+				fmt.Println("Failed to load config:", err)
+				os.Exit(1)
+			}
+			if err := app.ListEvents(os.Stdout, calendar, days, lang, format); err != nil {
+				fmt.Println("Failed to list events:", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&calendar, "calendar", "", "Comma-separated calendars (default: as on the calendar page)")
+	cmd.Flags().IntVar(&days, "days", 30, "Number of days ahead to list (0 for all)")
+	cmd.Flags().StringVar(&lang, "lang", "de", "Language of dates and labels (de, en)")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table, json, ics)")
+	return cmd
+}
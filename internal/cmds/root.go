@@ -26,6 +26,7 @@ var (
 	keyFile     string
 	domain      string
 	email       string
+	configFile  string
 	Version     = "dev"
 	showVersion bool
 )
@@ -41,6 +42,10 @@ type program struct{}
 func (p *program) Start(s service.Service) error {
 	go func() {
 		utils.SetupLogging(logfile)
+		if err := app.LoadConfig(configFile); err != nil {
+			slog.Error("failed to load config", "err", err.Error())
+			os.Exit(1)
+		}
 		go periodicUpdateCheck()
 		err := app.Server(port, sslPort, certFile, keyFile, domain, email)
 		if err != nil {
@@ -70,7 +75,12 @@ var rootCmd = &cobra.Command{
 			"keyFile", keyFile,
 			"domain", domain,
 			"email", email,
+			"config", configFile,
 		)
+		if err := app.LoadConfig(configFile); err != nil {
+			slog.Error("failed to load config", "err", err.Error())
+			os.Exit(1)
+		}
 		go periodicUpdateCheck()
 		err := app.Server(port, sslPort, certFile, keyFile, domain, email)
 		if err != nil {
@@ -89,9 +99,14 @@ func Execute() {
 	rootCmd.Flags().StringVar(&domain, "domain", "", "Domain for automatic SSL certificate generation (requires --email)")
 	rootCmd.Flags().StringVar(&email, "email", "", "Email for Let's Encrypt registration (required for --domain)")
 	rootCmd.Flags().BoolVar(&showVersion, "version", false, "Show version and exit")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Path to a JSON config file (webhooks, ...)")
 
 	rootCmd.AddCommand(installCmd())
 	rootCmd.AddCommand(updateCmd())
+	rootCmd.AddCommand(webhookCmd())
+	rootCmd.AddCommand(digestCmd())
+	rootCmd.AddCommand(calendarCmd())
+	rootCmd.AddCommand(eventsCmd())
 
 	slog.Info("ytc-server CLI started", "args", os.Args)
 	if err := rootCmd.Execute(); err != nil {
@@ -114,6 +129,7 @@ func installCmd() *cobra.Command {
 				"keyFile", keyFile,
 				"domain", domain,
 				"email", email,
+				"config", configFile,
 			)
 			exePath, err := os.Executable()
 			if err != nil {
@@ -131,6 +147,12 @@ func installCmd() *cobra.Command {
 			if email != "" {
 				argsList = append(argsList, "--email", email)
 			}
+			if configFile != "" {
+				if abs, err := filepath.Abs(configFile); err == nil {
+					configFile = abs
+				}
+				argsList = append(argsList, "--config", configFile)
+			}
 			svcConfig := &service.Config{
 				Name:        "ytc-server",
 				DisplayName: "YTC Server",
@@ -0,0 +1,45 @@
+package cmds
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/WillyWinkel/ytc/internal/app"
+	"github.com/WillyWinkel/ytc/internal/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slog"
+)
+
+func webhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Webhook helpers",
+	}
+	cmd.AddCommand(webhookReceiveCmd())
+	return cmd
+}
+
+func webhookReceiveCmd() *cobra.Command {
+	var (
+		listen string
+		secret string
+	)
+	cmd := &cobra.Command{
+		Use:   "receive",
+		Short: "Run a local webhook receiver that verifies signatures and prints payloads",
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.SetupLogging(logfile)
+			slog.Info("Starting webhook receiver", "listen", listen, "signed", secret != "")
+			fmt.Printf("Listening for webhooks on http://%s/\n", listen)
+			if err := http.ListenAndServe(listen, app.WebhookReceiver(secret, os.Stdout)); err != nil {
+				slog.Error("Webhook receiver failed", "err", err)
+				fmt.Println("Webhook receiver failed:", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:9000", "Address to listen on")
+	cmd.Flags().StringVar(&secret, "secret", "", "Shared secret used to verify the X-YTC-Signature header")
+	return cmd
+}